@@ -0,0 +1,211 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// associationRow is one associated object in command output
+type associationRow struct {
+	ToObjectType string                    `json:"toObjectType"`
+	ToObjectID   string                    `json:"toObjectId"`
+	Types        []hubspot.AssociationType `json:"associationTypes"`
+}
+
+// addAssociationCmds adds the associations, associate and disassociate
+// commands. kind is a pointer so custom object kinds resolved at runtime are
+// seen by the commands.
+func addAssociationCmds(parent *cobra.Command, kind *objectKind, idArg string) {
+	associationsCmd := &cobra.Command{
+		Use:   "associations " + idArg,
+		Short: fmt.Sprintf("List the associations of a %s", kind.singular),
+		Long: fmt.Sprintf(`List the objects a %s is associated with, including association labels.
+
+Without --to, the associations to every commonly associated object type are listed.`, kind.singular),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			targets, _ := cmd.Flags().GetStringSlice("to")
+			if len(targets) == 0 {
+				targets = kind.associationTargets
+			}
+			if len(targets) == 0 {
+				return fmt.Errorf("--to is required for %s", kind.plural)
+			}
+
+			var rows []associationRow
+			for _, target := range targets {
+				toType, err := resolveTypeName(cmd, client, target)
+				if err != nil {
+					return err
+				}
+
+				associations, err := client.ListAssociations(cmd.Context(), kind.Name, args[0], toType)
+				if err != nil {
+					return fmt.Errorf("failed to list %s associations: %w", target, err)
+				}
+				for _, assoc := range associations {
+					rows = append(rows, associationRow{
+						ToObjectType: toType,
+						ToObjectID:   strconv.FormatInt(assoc.ToObjectID, 10),
+						Types:        assoc.AssociationTypes,
+					})
+				}
+			}
+
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			return printAssociations(rows, format)
+		},
+	}
+	associationsCmd.Flags().StringSlice("to", nil, "Object type to list associations to (repeatable or comma-separated)")
+	associationsCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+
+	associateCmd := &cobra.Command{
+		Use:   "associate " + idArg + " [to-object-id]",
+		Short: fmt.Sprintf("Associate a %s with another object", kind.singular),
+		Long: fmt.Sprintf(`Associate a %s with another object. Without --label the default
+association is created; --label picks a labeled association by label or type ID.`, kind.singular),
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			target, _ := cmd.Flags().GetString("to")
+			toType, err := resolveTypeName(cmd, client, target)
+			if err != nil {
+				return err
+			}
+
+			var types []hubspot.AssociationType
+			labels, _ := cmd.Flags().GetStringSlice("label")
+			for _, label := range labels {
+				t, err := client.ResolveAssociationLabel(cmd.Context(), kind.Name, toType, label)
+				if err != nil {
+					return err
+				}
+				types = append(types, t)
+			}
+
+			if err := client.Associate(cmd.Context(), kind.Name, args[0], toType, args[1], types...); err != nil {
+				return fmt.Errorf("failed to associate %s: %w", kind.singular, err)
+			}
+
+			fmt.Printf("%s %s associated with %s %s.\n", capitalize(kind.singular), args[0], toType, args[1])
+			return nil
+		},
+	}
+	associateCmd.Flags().String("to", "", "Object type of the object to associate with (e.g. companies)")
+	associateCmd.Flags().StringSlice("label", nil, "Association label or type ID (repeatable)")
+	associateCmd.MarkFlagRequired("to")
+
+	disassociateCmd := &cobra.Command{
+		Use:   "disassociate " + idArg + " [to-object-id]",
+		Short: fmt.Sprintf("Remove the association between a %s and another object", kind.singular),
+		Long:  fmt.Sprintf(`Remove all associations between a %s and another object.`, kind.singular),
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			target, _ := cmd.Flags().GetString("to")
+			toType, err := resolveTypeName(cmd, client, target)
+			if err != nil {
+				return err
+			}
+
+			if err := client.Disassociate(cmd.Context(), kind.Name, args[0], toType, args[1]); err != nil {
+				return fmt.Errorf("failed to disassociate %s: %w", kind.singular, err)
+			}
+
+			fmt.Printf("%s %s disassociated from %s %s.\n", capitalize(kind.singular), args[0], toType, args[1])
+			return nil
+		},
+	}
+	disassociateCmd.Flags().String("to", "", "Object type of the associated object (e.g. companies)")
+	disassociateCmd.MarkFlagRequired("to")
+
+	parent.AddCommand(associationsCmd, associateCmd, disassociateCmd)
+}
+
+// resolveTypeName turns a user-supplied object type, such as "line-items" or
+// a custom object name, into the name used by the API
+func resolveTypeName(cmd *cobra.Command, client *hubspot.Client, name string) (string, error) {
+	objectType, _, err := client.ResolveObjectType(cmd.Context(), name)
+	if err != nil {
+		return "", err
+	}
+	return objectType.Name, nil
+}
+
+// associationLabels describes the association types of a row, using the label when there is one
+func associationLabels(types []hubspot.AssociationType) string {
+	var labels []string
+	for _, t := range types {
+		if t.Label != "" {
+			labels = append(labels, t.Label)
+		} else {
+			labels = append(labels, fmt.Sprintf("%s:%d", t.Category, t.TypeID))
+		}
+	}
+	return strings.Join(labels, ", ")
+}
+
+func printAssociations(rows []associationRow, format string) error {
+	headers := []string{"toObjectType", "toObjectId", "labels"}
+	if ok, err := printEncoded(format, rows, headers, func(row associationRow) []string {
+		return []string{row.ToObjectType, row.ToObjectID, associationLabels(row.Types)}
+	}); ok {
+		return err
+	}
+
+	// Table format
+	table := newTable(os.Stdout, "Object Type", "Object ID", "Labels")
+	for _, row := range rows {
+		if err := table.Append(row.ToObjectType, row.ToObjectID, associationLabels(row.Types)); err != nil {
+			return err
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return printTotal(os.Stdout, len(rows), "association(s)")
+}
+
+// printAssociatedIDs lists the associated object IDs of a v3 object read below its table
+func printAssociatedIDs(object hubspot.Object) {
+	types := make([]string, 0, len(object.Associations))
+	for toType := range object.Associations {
+		types = append(types, toType)
+	}
+	sort.Strings(types)
+
+	for _, toType := range types {
+		seen := make(map[string]bool)
+		var ids []string
+		for _, assoc := range object.Associations[toType].Results {
+			if !seen[assoc.ID] {
+				seen[assoc.ID] = true
+				ids = append(ids, assoc.ID)
+			}
+		}
+		fmt.Printf("Associated %s: %s\n", toType, strings.Join(ids, ", "))
+	}
+}
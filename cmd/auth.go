@@ -0,0 +1,316 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/obay/hscli/internal/credentials"
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// defaultScopes are requested by auth login when --scopes is not given
+var defaultScopes = []string{"oauth", "crm.objects.contacts.read", "crm.objects.contacts.write"}
+
+// oauthCredentials is the OAuth state persisted between runs
+type oauthCredentials struct {
+	ClientID     string         `json:"client_id"`
+	ClientSecret string         `json:"client_secret"`
+	RedirectURL  string         `json:"redirect_url"`
+	Token        *hubspot.Token `json:"token"`
+}
+
+func (c *oauthCredentials) config() hubspot.OAuthConfig {
+	return hubspot.OAuthConfig{
+		ClientID:     c.ClientID,
+		ClientSecret: c.ClientSecret,
+		RedirectURL:  c.RedirectURL,
+		TokenURL:     viper.GetString("oauth.token-url"),
+	}
+}
+
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Manage authentication",
+	Long:  `Log in to HubSpot with OAuth 2.0, store encrypted API keys and inspect the active credential.`,
+}
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Log in with a HubSpot public app",
+	Long: `Log in with a HubSpot public app using the OAuth 2.0 authorization code flow.
+
+hscli opens the authorization URL in your browser and receives the code on
+a loopback callback server at http://localhost:PORT/oauth-callback, which
+must be registered as a redirect URL of the app. The refresh token is
+stored and access tokens are refreshed transparently afterwards.
+
+The client ID and secret can also be set as oauth.client-id and
+oauth.client-secret in the config file.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		clientID, _ := cmd.Flags().GetString("client-id")
+		if clientID == "" {
+			clientID = viper.GetString("oauth.client-id")
+		}
+		clientSecret, _ := cmd.Flags().GetString("client-secret")
+		if clientSecret == "" {
+			clientSecret = viper.GetString("oauth.client-secret")
+		}
+		if clientID == "" || clientSecret == "" {
+			return fmt.Errorf("client ID and secret are required. Use --client-id and --client-secret or set oauth.client-id and oauth.client-secret in the config file")
+		}
+
+		scopes, _ := cmd.Flags().GetStringSlice("scopes")
+		port, _ := cmd.Flags().GetInt("port")
+		noBrowser, _ := cmd.Flags().GetBool("no-browser")
+
+		config := hubspot.OAuthConfig{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			Scopes:       scopes,
+			AuthURL:      viper.GetString("oauth.auth-url"),
+			TokenURL:     viper.GetString("oauth.token-url"),
+		}
+
+		addr := fmt.Sprintf("localhost:%d", port)
+		token, redirectURL, err := hubspot.LoginLoopback(cmd.Context(), config, addr, func(authURL string) error {
+			fmt.Fprintf(os.Stderr, "Open this URL to authorize hscli:\n\n  %s\n\n", authURL)
+			if !noBrowser {
+				if err := openBrowser(authURL); err != nil {
+					fmt.Fprintf(os.Stderr, "Could not open a browser (%v); open the URL manually.\n", err)
+				}
+			}
+			fmt.Fprintln(os.Stderr, "Waiting for authorization...")
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("login failed: %w", err)
+		}
+
+		creds := &oauthCredentials{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			RedirectURL:  redirectURL,
+			Token:        token,
+		}
+		if err := saveOAuthCredentials(creds); err != nil {
+			return err
+		}
+
+		fmt.Printf("Logged in successfully. Credentials saved to %s\n", oauthCredentialsPath())
+		return nil
+	},
+}
+
+var logoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove stored credentials",
+	Long:  `Remove the OAuth credentials stored by hscli auth login and the encrypted API key stored by hscli auth store.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		removed, err := removeCredentialStore()
+		if err != nil {
+			return err
+		}
+
+		if err := os.Remove(oauthCredentialsPath()); err != nil {
+			if !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("failed to remove credentials: %w", err)
+			}
+		} else {
+			removed = true
+		}
+
+		if !removed {
+			fmt.Println("Not logged in.")
+			return nil
+		}
+		fmt.Println("Logged out.")
+		return nil
+	},
+}
+
+var storeCmd = &cobra.Command{
+	Use:   "store",
+	Short: "Store an API key in a passphrase-encrypted file",
+	Long: `Store an API key in a file encrypted with a passphrase (PBKDF2 and AES-256-GCM).
+
+The API key is read from the terminal, or from the first line of stdin when
+it is not a terminal. The passphrase is read from the HSCLI_PASSPHRASE env
+var or the terminal, and is asked for again whenever hscli needs the key.
+The file is only used when no other API key is configured.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		apiKey, err := readSecret("API key: ")
+		if err != nil {
+			return err
+		}
+		apiKey = strings.TrimSpace(apiKey)
+		if apiKey == "" {
+			return fmt.Errorf("API key must not be empty")
+		}
+
+		passphrase, err := readPassphrase("Passphrase: ")
+		if err != nil {
+			return err
+		}
+		if os.Getenv("HSCLI_PASSPHRASE") == "" {
+			confirm, err := readSecret("Confirm passphrase: ")
+			if err != nil {
+				return err
+			}
+			if confirm != passphrase {
+				return fmt.Errorf("passphrases do not match")
+			}
+		}
+
+		path := credentialStorePath()
+		if err := credentials.WriteFile(path, apiKey, passphrase); err != nil {
+			return err
+		}
+
+		fmt.Printf("API key encrypted and saved to %s\n", path)
+		return nil
+	},
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the active credential comes from",
+	Long: `Show the active profile and where the credential used for API requests
+comes from. Credentials are looked up in this order:
+
+  1. --api-key flag
+  2. HUBSPOT_API_KEY env var
+  3. api-key in the config file or active profile
+  4. api-key-command in the config file or active profile
+  5. encrypted credential file (hscli auth store)
+  6. OAuth login (hscli auth login)`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if activeProfile != "" {
+			fmt.Printf("Profile:    %s (selected by %s)\n", activeProfile, profileSource)
+		} else {
+			fmt.Println("Profile:    (none)")
+		}
+
+		cred, err := resolveCredential(cmd.Context())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Source:     %s\n", cred.source)
+
+		if cred.tokens == nil {
+			fmt.Printf("Credential: API key %s\n", credentials.Mask(cred.apiKey))
+			return nil
+		}
+
+		creds, err := loadOAuthCredentials()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Credential: OAuth app %s\n", creds.ClientID)
+		if creds.Token.Valid() {
+			fmt.Printf("Expires:    %s\n", creds.Token.Expiry.Local().Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Println("Expires:    expired; refreshed on the next request")
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+
+	authCmd.AddCommand(loginCmd)
+	loginCmd.Flags().String("client-id", "", "Client ID of the HubSpot public app")
+	loginCmd.Flags().String("client-secret", "", "Client secret of the HubSpot public app")
+	loginCmd.Flags().StringSlice("scopes", defaultScopes, "OAuth scopes to request")
+	loginCmd.Flags().Int("port", 8085, "Port of the loopback callback server")
+	loginCmd.Flags().Bool("no-browser", false, "Print the authorization URL instead of opening a browser")
+
+	authCmd.AddCommand(logoutCmd)
+	authCmd.AddCommand(storeCmd)
+	authCmd.AddCommand(statusCmd)
+}
+
+// oauthCredentialsPath returns where OAuth credentials are stored
+func oauthCredentialsPath() string {
+	if path := viper.GetString("oauth.token-file"); path != "" {
+		return path
+	}
+	name := ".hscli-oauth.json"
+	if activeProfile != "" {
+		// Each profile logs in to its own portal
+		name = ".hscli-oauth-" + activeProfile + ".json"
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(home, name)
+}
+
+// loadOAuthCredentials reads stored OAuth credentials, returning nil if there are none
+func loadOAuthCredentials() (*oauthCredentials, error) {
+	data, err := os.ReadFile(oauthCredentialsPath())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read OAuth credentials: %w", err)
+	}
+
+	var creds oauthCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse OAuth credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+// saveOAuthCredentials writes OAuth credentials readable only by the current user
+func saveOAuthCredentials(creds *oauthCredentials) error {
+	data, err := json.MarshalIndent(creds, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(oauthCredentialsPath(), data, 0o600); err != nil {
+		return fmt.Errorf("failed to save OAuth credentials: %w", err)
+	}
+	return nil
+}
+
+// oauthTokenSource returns a token source backed by the stored OAuth
+// credentials, or nil if the user has not logged in
+func oauthTokenSource() (hubspot.TokenSource, error) {
+	creds, err := loadOAuthCredentials()
+	if err != nil || creds == nil || creds.Token == nil {
+		return nil, err
+	}
+
+	return hubspot.NewOAuthTokenSource(creds.config(), creds.Token, func(token *hubspot.Token) error {
+		creds.Token = token
+		return saveOAuthCredentials(creds)
+	}), nil
+}
+
+// openBrowser opens url in the user's default browser
+func openBrowser(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("%s: %w", strings.Join(cmd.Args[:1], ""), err)
+	}
+	return nil
+}
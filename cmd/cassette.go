@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/obay/hscli/internal/cassette"
+)
+
+// cassetteRT is shared by every client of the process so a single cassette
+// covers all requests of a command
+var cassetteRT http.RoundTripper
+
+// cassetteTransport returns the recording or replaying transport selected
+// by --record or --replay, or nil when neither is set
+func cassetteTransport() (http.RoundTripper, error) {
+	if cassetteRT != nil {
+		return cassetteRT, nil
+	}
+
+	switch {
+	case recordFile != "" && replayFile != "":
+		return nil, fmt.Errorf("--record and --replay cannot be used together")
+	case recordFile != "":
+		recorder, err := cassette.NewRecorder(recordFile, nil)
+		if err != nil {
+			return nil, err
+		}
+		cassetteRT = recorder
+	case replayFile != "":
+		c, err := cassette.Load(replayFile)
+		if err != nil {
+			return nil, err
+		}
+		cassetteRT = cassette.NewReplayer(c)
+	}
+	return cassetteRT, nil
+}
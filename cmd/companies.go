@@ -0,0 +1,29 @@
+package cmd
+
+import "github.com/obay/hscli/internal/hubspot"
+
+// companiesKind describes the companies command tree
+var companiesKind = objectKind{
+	ObjectType: hubspot.Companies,
+	command:    "companies",
+	singular:   "company",
+	plural:     "companies",
+	fields: []fieldFlag{
+		{name: "name", shorthand: "n", property: "name", usage: "Company name"},
+		{name: "domain", shorthand: "d", property: "domain", usage: "Company domain (e.g., example.com)"},
+		{name: "industry", property: "industry", usage: "Industry"},
+		{name: "lifecycle-stage", property: "lifecyclestage", usage: "Lifecycle stage (e.g., lead, customer)"},
+	},
+	associationTargets: []string{"contacts", "deals", "tickets"},
+	columns: []column{
+		{header: "Name", property: "name"},
+		{header: "Domain", property: "domain"},
+		{header: "Industry", property: "industry"},
+		{header: "City", property: "city"},
+		{header: "Lifecycle Stage", property: "lifecyclestage"},
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newObjectCmd(companiesKind))
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/obay/hscli/internal/credentials"
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+// credential is the resolved authentication for API requests
+type credential struct {
+	apiKey string
+	tokens hubspot.TokenSource
+	// source describes where the credential came from, for auth status
+	source string
+}
+
+// resolveCredential finds the credential to use, in order: --api-key,
+// HUBSPOT_API_KEY, api-key in the config file or profile, api-key-command,
+// the encrypted credential file and finally the OAuth login
+func resolveCredential(ctx context.Context) (*credential, error) {
+	if profileErr != nil {
+		return nil, profileErr
+	}
+
+	switch {
+	case rootCmd.PersistentFlags().Changed("api-key"):
+		return &credential{apiKey: viper.GetString("api-key"), source: "--api-key flag"}, nil
+	case os.Getenv("HUBSPOT_API_KEY") != "":
+		return &credential{apiKey: os.Getenv("HUBSPOT_API_KEY"), source: "HUBSPOT_API_KEY env var"}, nil
+	case viper.GetString("api-key") != "":
+		return &credential{apiKey: viper.GetString("api-key"), source: "api-key in " + configLocation()}, nil
+	}
+
+	if command := viper.GetString("api-key-command"); command != "" {
+		apiKey, err := credentials.FromCommand(ctx, command)
+		if err != nil {
+			return nil, err
+		}
+		return &credential{apiKey: apiKey, source: "api-key-command in " + configLocation()}, nil
+	}
+
+	path := credentialStorePath()
+	if _, err := os.Stat(path); err == nil {
+		apiKey, err := readCredentialStore(path)
+		if err != nil {
+			return nil, err
+		}
+		return &credential{apiKey: apiKey, source: "encrypted credential file " + path}, nil
+	}
+
+	tokens, err := oauthTokenSource()
+	if err != nil {
+		return nil, err
+	}
+	if tokens != nil {
+		return &credential{tokens: tokens, source: "OAuth login " + oauthCredentialsPath()}, nil
+	}
+
+	return nil, fmt.Errorf("API key is required. Set HUBSPOT_API_KEY env var, use --api-key flag, configure api-key-command, or run hscli auth login")
+}
+
+// configLocation names the config file, and the profile when the setting may come from one
+func configLocation() string {
+	if activeProfile != "" {
+		return fmt.Sprintf("%s (profile %s)", configFilePath(), activeProfile)
+	}
+	return configFilePath()
+}
+
+// credentialStorePath returns where hscli auth store keeps the encrypted API key
+func credentialStorePath() string {
+	if path := viper.GetString("credential-file"); path != "" {
+		return path
+	}
+	name := ".hscli-credentials.enc"
+	if activeProfile != "" {
+		name = ".hscli-credentials-" + activeProfile + ".enc"
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(home, name)
+}
+
+// cachedStoreKeys holds decrypted API keys so the passphrase is asked once per process
+var cachedStoreKeys = make(map[string]string)
+
+func readCredentialStore(path string) (string, error) {
+	if apiKey, ok := cachedStoreKeys[path]; ok {
+		return apiKey, nil
+	}
+
+	passphrase, err := readPassphrase(fmt.Sprintf("Passphrase for %s: ", path))
+	if err != nil {
+		return "", err
+	}
+	apiKey, err := credentials.ReadFile(path, passphrase)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+
+	cachedStoreKeys[path] = apiKey
+	return apiKey, nil
+}
+
+func removeCredentialStore() (bool, error) {
+	if err := os.Remove(credentialStorePath()); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to remove credential file: %w", err)
+	}
+	return true, nil
+}
+
+// readPassphrase reads a passphrase from HSCLI_PASSPHRASE or, failing that,
+// from the terminal with echo turned off
+func readPassphrase(prompt string) (string, error) {
+	if passphrase := os.Getenv("HSCLI_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+	if !isTerminal(os.Stdin) {
+		return "", errors.New("a passphrase is required; set HSCLI_PASSPHRASE when not running in a terminal")
+	}
+	return readSecret(prompt)
+}
+
+// readSecret reads a line from stdin, prompting on stderr and without
+// echoing it when stdin is a terminal
+func readSecret(prompt string) (string, error) {
+	if isTerminal(os.Stdin) {
+		fmt.Fprint(os.Stderr, prompt)
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read input: %w", err)
+		}
+		return string(secret), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func isTerminal(f *os.File) bool {
+	return term.IsTerminal(int(f.Fd()))
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/spf13/cobra"
+)
+
+var customObjectsCmd = &cobra.Command{
+	Use:   "objects [object-type] [command]",
+	Short: "Manage HubSpot custom objects",
+	Long: `Manage records of any CRM object type, including custom objects.
+
+The object type can be a custom object type ID (e.g. 2-123456), its fully
+qualified name, its name or label (e.g. subscriptions), or a standard
+object name such as companies. Custom object types are discovered
+through the schemas API.
+
+Examples:
+  hscli objects subscriptions list --all
+  hscli objects 2-123456 get 1001 --format json
+  hscli objects devices create --set serial=AB-1 --set model=X200
+  hscli objects devices query "serial=AB-1"`,
+	// Flags belong to the per-type command tree built at runtime
+	DisableFlagParsing: true,
+	SilenceUsage:       true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(args) == 0 || args[0] == "-h" || args[0] == "--help" {
+			return cmd.Help()
+		}
+		if strings.HasPrefix(args[0], "-") {
+			return fmt.Errorf("the object type must be given before any flags, e.g. hscli objects subscriptions list")
+		}
+
+		name := args[0]
+		tree := newObjectCmd(objectKind{
+			ObjectType: hubspot.ObjectType{Name: name},
+			command:    name,
+			singular:   name,
+			plural:     name,
+			resolve: func(cmd *cobra.Command) (objectKind, error) {
+				client, err := newClient()
+				if err != nil {
+					return objectKind{}, err
+				}
+				return resolveObjectKind(cmd, client, name)
+			},
+		})
+
+		// Mirror the hscli → objects path so help and usage show the full command line
+		root := &cobra.Command{Use: rootCmd.Use, SilenceErrors: true}
+		root.PersistentFlags().AddFlagSet(rootCmd.PersistentFlags())
+		parent := &cobra.Command{Use: cmd.Name()}
+		parent.AddCommand(tree)
+		root.AddCommand(parent)
+		root.SetArgs(append([]string{cmd.Name()}, args...))
+		return root.ExecuteContext(cmd.Context())
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(customObjectsCmd)
+}
+
+// standardKinds lists the command definitions of the built-in object types
+func standardKinds() []objectKind {
+	return []objectKind{contactsKind, companiesKind, dealsKind, ticketsKind, productsKind, lineItemsKind}
+}
+
+// resolveObjectKind looks up a standard or custom object type by name or ID
+func resolveObjectKind(cmd *cobra.Command, client *hubspot.Client, name string) (objectKind, error) {
+	objectType, schema, err := client.ResolveObjectType(cmd.Context(), name)
+	if err != nil {
+		return objectKind{}, err
+	}
+
+	if schema != nil {
+		return kindFromSchema(*schema), nil
+	}
+
+	for _, kind := range standardKinds() {
+		if kind.Name == objectType.Name {
+			return kind, nil
+		}
+	}
+	return objectKind{ObjectType: objectType, command: name, singular: name, plural: name}, nil
+}
+
+// kindFromSchema builds a command definition for a custom object schema
+func kindFromSchema(schema hubspot.ObjectSchema) objectKind {
+	objectType := schema.ObjectType()
+
+	labels := make(map[string]string, len(schema.Properties))
+	for _, prop := range schema.Properties {
+		labels[prop.Name] = prop.Label
+	}
+
+	var columns []column
+	for _, name := range objectType.DefaultProperties {
+		header := labels[name]
+		if header == "" {
+			header = name
+		}
+		columns = append(columns, column{header: header, property: name})
+	}
+
+	singular := strings.ToLower(schema.Labels.Singular)
+	if singular == "" {
+		singular = schema.Name
+	}
+	plural := strings.ToLower(schema.Labels.Plural)
+	if plural == "" {
+		plural = schema.Name
+	}
+
+	return objectKind{
+		ObjectType: objectType,
+		command:    schema.Name,
+		singular:   singular,
+		plural:     plural,
+		columns:    columns,
+	}
+}
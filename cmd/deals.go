@@ -0,0 +1,30 @@
+package cmd
+
+import "github.com/obay/hscli/internal/hubspot"
+
+// dealsKind describes the deals command tree
+var dealsKind = objectKind{
+	ObjectType: hubspot.Deals,
+	command:    "deals",
+	singular:   "deal",
+	plural:     "deals",
+	fields: []fieldFlag{
+		{name: "name", shorthand: "n", property: "dealname", usage: "Deal name"},
+		{name: "amount", property: "amount", usage: "Deal amount"},
+		{name: "stage", property: "dealstage", usage: "Deal stage (e.g., appointmentscheduled, closedwon)"},
+		{name: "pipeline", property: "pipeline", usage: "Pipeline ID (e.g., default)"},
+		{name: "close-date", property: "closedate", usage: "Close date (e.g., 2025-03-31)"},
+	},
+	associationTargets: []string{"contacts", "companies", "line_items", "tickets"},
+	columns: []column{
+		{header: "Name", property: "dealname"},
+		{header: "Amount", property: "amount"},
+		{header: "Stage", property: "dealstage"},
+		{header: "Pipeline", property: "pipeline"},
+		{header: "Close Date", property: "closedate"},
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newObjectCmd(dealsKind))
+}
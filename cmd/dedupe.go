@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/obay/hscli/internal/dedupe"
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var dedupeContactsCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find duplicate contacts",
+	Long: `Scan every contact for likely duplicates and print them as scored
+clusters for review. Nothing is merged or changed.
+
+Contacts match when:
+  - their email addresses are equal ignoring case (score 1.0)
+  - their Gmail addresses are equal once dots and +tags are removed, e.g.
+    Jon.Smith+news@gmail.com and jonsmith@googlemail.com (score 0.95)
+  - their names and companies are both at least --min-score similar,
+    ignoring accents, punctuation and legal forms such as Inc or GmbH
+    (scored 60% name and 40% company similarity)
+
+Matching contacts are grouped into clusters, scored by their best match.
+--offline scans the local mirror kept by hscli sync instead of HubSpot.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		minScore, _ := cmd.Flags().GetFloat64("min-score")
+		if minScore <= 0 || minScore > 1 {
+			return fmt.Errorf("invalid --min-score %v, expected a number between 0 and 1", minScore)
+		}
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		var contacts []dedupe.Contact
+		if isOffline(cmd) {
+			contacts, err = mirroredContacts()
+		} else {
+			contacts, err = listContactsForDedupe(cmd)
+		}
+		if err != nil {
+			return err
+		}
+
+		return printClusters(dedupe.Find(contacts, minScore), format)
+	},
+}
+
+func init() {
+	contactsCmd.AddCommand(dedupeContactsCmd)
+
+	dedupeContactsCmd.Flags().Float64("min-score", 0.85, "Minimum name and company similarity, from 0 to 1, for contacts to match by name")
+	dedupeContactsCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+	addOfflineFlag(dedupeContactsCmd, contactsKind)
+}
+
+// listContactsForDedupe lists every contact with the properties duplicates
+// are found by
+func listContactsForDedupe(cmd *cobra.Command) ([]dedupe.Contact, error) {
+	client, err := newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	tty := term.IsTerminal(int(os.Stderr.Fd()))
+	var contacts []dedupe.Contact
+	it := client.ListIterator(cmd.Context(), hubspot.Contacts.Name, 100, dedupe.Properties)
+	for it.Next() {
+		contacts = append(contacts, dedupe.FromObject(it.Object()))
+		if tty && len(contacts)%100 == 0 {
+			fmt.Fprintf(os.Stderr, "\rScanned %d contacts...", len(contacts))
+		}
+	}
+	if tty {
+		fmt.Fprint(os.Stderr, "\r\033[K")
+	}
+	if err := it.Err(); err != nil {
+		return nil, interrupted(cmd, fmt.Errorf("failed to list contacts: %w", err), len(contacts))
+	}
+	return contacts, nil
+}
+
+// mirroredContacts reads the contacts of the local mirror
+func mirroredContacts() ([]dedupe.Contact, error) {
+	m, err := openMirror(contactsKind)
+	if err != nil {
+		return nil, err
+	}
+	if missing := m.Missing(dedupe.Properties); len(missing) > 0 {
+		return nil, fmt.Errorf("the local mirror does not have %s; add them with hscli sync --properties",
+			strings.Join(missing, ", "))
+	}
+
+	objects := m.Objects()
+	contacts := make([]dedupe.Contact, len(objects))
+	for i, object := range objects {
+		contacts[i] = dedupe.FromObject(object)
+	}
+	return contacts, nil
+}
+
+func printClusters(clusters []dedupe.Cluster, format string) error {
+	headers := []string{"score", "reasons", "ids", "emails"}
+	if ok, err := printEncoded(format, clusters, headers, func(cl dedupe.Cluster) []string {
+		var ids, emails []string
+		for _, c := range cl.Members {
+			ids = append(ids, c.ID)
+			emails = append(emails, c.Email)
+		}
+		return []string{formatScore(cl.Score), strings.Join(cl.Reasons, ";"), strings.Join(ids, ";"), strings.Join(emails, ";")}
+	}); ok {
+		return err
+	}
+
+	// Table format, with the cluster's number, score and reasons on its first row
+	table := newTable(os.Stdout, "Cluster", "Score", "Match", "ID", "Email", "Name", "Company")
+	for i, cl := range clusters {
+		for j, c := range cl.Members {
+			number, score, reasons := "", "", ""
+			if j == 0 {
+				number, score, reasons = strconv.Itoa(i+1), formatScore(cl.Score), strings.Join(cl.Reasons, ", ")
+			}
+			if err := table.Append(number, score, reasons, c.ID, c.Email, c.Name(), c.Company); err != nil {
+				return err
+			}
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return printTotal(os.Stdout, len(clusters), "duplicate cluster(s)")
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', 2, 64)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/spf13/cobra"
+)
+
+// Process exit codes, so scripts can tell failure classes apart
+const (
+	exitOK           = 0
+	exitError        = 1
+	exitUnauthorized = 3
+	exitMissingScope = 4
+	exitNotFound     = 5
+	exitValidation   = 6
+	exitConflict     = 7
+	exitRateLimited  = 8
+	exitServerError  = 9
+	exitInterrupted  = 130
+)
+
+// exitCode maps an error returned by a command to a process exit code
+func exitCode(err error) int {
+	if err == nil {
+		return exitOK
+	}
+
+	if errors.Is(err, context.Canceled) {
+		return exitInterrupted
+	}
+
+	apiErr, ok := hubspot.AsAPIError(err)
+	if !ok {
+		return exitError
+	}
+
+	switch {
+	case apiErr.IsUnauthorized():
+		return exitUnauthorized
+	case apiErr.IsMissingScope():
+		return exitMissingScope
+	case apiErr.IsNotFound():
+		return exitNotFound
+	case apiErr.IsRateLimited():
+		return exitRateLimited
+	case apiErr.IsConflict():
+		return exitConflict
+	case apiErr.IsValidation():
+		return exitValidation
+	case apiErr.StatusCode >= 500:
+		return exitServerError
+	}
+	return exitError
+}
+
+// printErrorDetails writes per-field validation errors and the correlation ID
+// HubSpot support asks for when a command fails with an API error
+func printErrorDetails(w io.Writer, err error) {
+	apiErr, ok := hubspot.AsAPIError(err)
+	if !ok {
+		return
+	}
+
+	for _, detail := range apiErr.Errors {
+		keys := make([]string, 0, len(detail.Context))
+		for key := range detail.Context {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		var fields []string
+		for _, key := range keys {
+			fields = append(fields, detail.Context[key]...)
+		}
+		if len(fields) > 0 {
+			fmt.Fprintf(w, "  - %s (%s)\n", detail.Message, strings.Join(fields, ", "))
+		} else {
+			fmt.Fprintf(w, "  - %s\n", detail.Message)
+		}
+	}
+	if apiErr.CorrelationID != "" {
+		fmt.Fprintf(w, "Correlation ID: %s\n", apiErr.CorrelationID)
+	}
+}
+
+// interrupted reports how many records were processed when a command is
+// cancelled with Ctrl-C, and otherwise returns err unchanged
+func interrupted(cmd *cobra.Command, err error, processed int) error {
+	if cmd.Context().Err() == nil {
+		return err
+	}
+	return fmt.Errorf("interrupted after processing %d record(s): %w", processed, cmd.Context().Err())
+}
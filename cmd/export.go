@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/obay/hscli/internal/export"
+	"github.com/obay/hscli/internal/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// exportFormats are the formats an export can be appended to when resumed
+var exportFormats = []string{output.NDJSON, output.CSV, output.TSV}
+
+// addExportCmd adds the export command to an object command
+func addExportCmd(parent *cobra.Command, kind *objectKind) {
+	exportCmd := &cobra.Command{
+		Use:   "export",
+		Short: fmt.Sprintf("Export all %s to a file", kind.plural),
+		Long: fmt.Sprintf(`Export every %s to an NDJSON, CSV or TSV file, writing each page as it
+arrives. The format follows the file extension unless --format is given.
+
+Progress is saved to FILE.checkpoint.json after every page. If the export
+stops, e.g. on a network error or Ctrl-C, run the same command with
+--resume to continue from the last saved page. When the export finishes,
+the checkpoint records the number of %s and the file's SHA-256 checksum.`, kind.singular, kind.plural),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out, _ := cmd.Flags().GetString("out")
+			resume, _ := cmd.Flags().GetBool("resume")
+			cpPath := export.CheckpointPath(out)
+
+			cp, err := export.LoadCheckpoint(cpPath)
+			if err != nil {
+				return err
+			}
+
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			var f *os.File
+			switch {
+			case resume && cp == nil:
+				return fmt.Errorf("no checkpoint found at %s, there is no export to resume", cpPath)
+
+			case resume && cp.Complete:
+				fmt.Printf("The export to %s is already complete.\n", out)
+				printExportSummary(out, cp)
+				return nil
+
+			case resume:
+				if cp.ObjectType != kind.Name {
+					return fmt.Errorf("%s is an export of %s, not %s", out, cp.ObjectType, kind.plural)
+				}
+				f, err = export.Reopen(out, cp)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(os.Stderr, "Resuming after %d %s.\n", cp.Count, kind.noun(cp.Count))
+
+			default:
+				if cp != nil && !cp.Complete {
+					return fmt.Errorf("%s has an unfinished export; use --resume to continue it, or delete %s to start over", out, cpPath)
+				}
+
+				format, err := exportFormat(cmd, out)
+				if err != nil {
+					return err
+				}
+				properties, _, err := requestedProperties(cmd, client, *kind)
+				if err != nil {
+					return err
+				}
+
+				cp = &export.Checkpoint{
+					ObjectType: kind.Name,
+					Format:     format,
+					Properties: properties,
+					StartedAt:  time.Now().UTC(),
+				}
+				f, err = export.Create(out, cp)
+				if err != nil {
+					return err
+				}
+			}
+			defer f.Close()
+
+			// Every exported property gets a column, whatever the table shows
+			view := *kind
+			view.columns, view.columnsChosen = []column{idColumn}, true
+			for _, name := range cp.Properties {
+				view.columns = append(view.columns, column{header: name, property: name})
+			}
+			var headers []string
+			for _, col := range view.columns {
+				headers = append(headers, col.property)
+			}
+			enc := output.NewEncoder(f, cp.Format, headers)
+			if cp.Size > 0 {
+				enc.SkipHeader()
+			}
+
+			var progress func(int)
+			if term.IsTerminal(int(os.Stderr.Fd())) {
+				progress = func(count int) {
+					fmt.Fprintf(os.Stderr, "\rExported %d %s...", count, kind.noun(count))
+				}
+			}
+
+			it := client.ListIteratorFrom(cmd.Context(), kind.Name, 100, cp.Properties, cp.After)
+			err = export.Run(it, f, &encodedObjectWriter{enc: enc, kind: view}, cp, cpPath, progress)
+			if progress != nil {
+				fmt.Fprintln(os.Stderr)
+			}
+			if err != nil {
+				err = fmt.Errorf("failed to export %s: %w; run again with --resume to continue", kind.plural, err)
+				return interrupted(cmd, err, cp.Count)
+			}
+
+			printExportSummary(out, cp)
+			return nil
+		},
+	}
+	exportCmd.Flags().StringP("out", "o", "", "File to export to, e.g. contacts.ndjson or contacts.csv")
+	exportCmd.Flags().StringP("format", "f", "", fmt.Sprintf("Export format (%s); inferred from the file extension by default", strings.Join(exportFormats, ", ")))
+	exportCmd.Flags().Bool("resume", false, "Continue an interrupted export from its checkpoint")
+	exportCmd.Flags().String("properties", "", "Comma-separated properties to export; @name expands a property set from the config file")
+	exportCmd.Flags().Bool("all-properties", false, "Export every property defined for the object type")
+	exportCmd.MarkFlagsMutuallyExclusive("properties", "all-properties")
+	exportCmd.MarkFlagsMutuallyExclusive("resume", "format")
+	exportCmd.MarkFlagsMutuallyExclusive("resume", "properties")
+	exportCmd.MarkFlagsMutuallyExclusive("resume", "all-properties")
+	exportCmd.MarkFlagRequired("out")
+
+	parent.AddCommand(exportCmd)
+}
+
+// exportFormat returns the --format flag or the format matching the
+// extension of the export file
+func exportFormat(cmd *cobra.Command, out string) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if format == "" {
+		format = strings.ToLower(strings.TrimPrefix(filepath.Ext(out), "."))
+		if format == "jsonl" {
+			format = output.NDJSON
+		}
+	}
+
+	for _, f := range exportFormats {
+		if format == f {
+			return format, nil
+		}
+	}
+	if format == "" {
+		return "", fmt.Errorf("cannot tell the format of %s, use --format (%s)", out, strings.Join(exportFormats, ", "))
+	}
+	return "", fmt.Errorf("cannot export as %q, expected one of %s", format, strings.Join(exportFormats, ", "))
+}
+
+func printExportSummary(out string, cp *export.Checkpoint) {
+	fmt.Printf("Exported %d %s to %s\n", cp.Count, cp.ObjectType, out)
+	fmt.Printf("SHA-256: %s\n", cp.SHA256)
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/importer"
+	"github.com/spf13/cobra"
+)
+
+var importContactsCmd = &cobra.Command{
+	Use:   "import [file.csv]",
+	Short: "Import contacts from a CSV file",
+	Long: `Create or update contacts from a CSV file with a header line, using
+batch upserts with the email address as the key: existing contacts are
+updated and new ones created.
+
+Columns are mapped to properties with --map, e.g.
+--map "E-mail=email,First=firstname". Columns whose header matches a
+property's name or label are mapped automatically and other columns are
+ignored. Empty cells leave existing values unchanged.
+
+Every row is checked against the portal's property definitions before
+anything is sent. Rows that fail validation or are rejected by HubSpot are
+written to a CSV file with an error column, which can be fixed and imported
+again. Use - to read the CSV from stdin.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		spec, _ := cmd.Flags().GetString("map")
+		mapping, err := importer.ParseMapping(spec)
+		if err != nil {
+			return err
+		}
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+		rejectsPath, _ := cmd.Flags().GetString("rejects")
+		if rejectsPath == "" {
+			rejectsPath = defaultRejectsPath(args[0])
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		definitions, err := client.ListObjectProperties(cmd.Context(), hubspot.Contacts.Name)
+		if err != nil {
+			return fmt.Errorf("failed to list properties: %w", err)
+		}
+
+		var in io.Reader = os.Stdin
+		if args[0] != "-" {
+			f, err := os.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("failed to open CSV file: %w", err)
+			}
+			defer f.Close()
+			in = f
+		}
+
+		file, err := importer.Read(in, mapping, definitions, "email")
+		if err != nil {
+			return err
+		}
+
+		columns := make([]string, 0, len(file.Columns))
+		for i, column := range file.Header {
+			if name, ok := file.Columns[i]; ok {
+				columns = append(columns, fmt.Sprintf("%s → %s", column, name))
+			}
+		}
+		fmt.Fprintf(os.Stderr, "Mapping: %s\n", strings.Join(columns, ", "))
+		if len(file.Ignored) > 0 {
+			fmt.Fprintf(os.Stderr, "Ignored columns: %s\n", strings.Join(file.Ignored, ", "))
+		}
+
+		valid := file.Valid()
+		if dryRun {
+			fmt.Printf("Dry run: %d row(s) valid, %d rejected; nothing was imported.\n", len(valid), len(file.Rejected()))
+			return writeRejects(rejectsPath, file)
+		}
+
+		summary, importErr := importer.Import(cmd.Context(), client, hubspot.Contacts.Name, "email", valid)
+		fmt.Printf("%d contact(s) created, %d updated, %d row(s) rejected.\n",
+			summary.Created, summary.Updated, len(file.Rejected()))
+
+		if err := writeRejects(rejectsPath, file); err != nil {
+			return err
+		}
+		if importErr != nil {
+			processed := summary.Created + summary.Updated + summary.Rejected
+			return interrupted(cmd, fmt.Errorf("failed to import contacts after %d row(s): %w", processed, importErr), processed)
+		}
+		return nil
+	},
+}
+
+func init() {
+	contactsCmd.AddCommand(importContactsCmd)
+
+	importContactsCmd.Flags().String("map", "", "Map CSV columns to properties as column=property, comma-separated")
+	importContactsCmd.Flags().Bool("dry-run", false, "Validate the file without importing anything")
+	importContactsCmd.Flags().String("rejects", "", "Where to write rejected rows (default FILE-rejected.csv)")
+}
+
+// defaultRejectsPath names the rejected-rows file after the imported file
+func defaultRejectsPath(path string) string {
+	if path == "-" {
+		return "rejected.csv"
+	}
+	return strings.TrimSuffix(path, filepath.Ext(path)) + "-rejected.csv"
+}
+
+// writeRejects writes the rejected rows, if any, and tells the user where
+func writeRejects(path string, file *importer.File) error {
+	rejected := file.Rejected()
+	if len(rejected) == 0 {
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create rejected rows file: %w", err)
+	}
+	if err := importer.WriteRejected(f, file.Header, rejected); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write rejected rows: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write rejected rows: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Rejected rows written to %s\n", path)
+	return nil
+}
@@ -0,0 +1,29 @@
+package cmd
+
+import "github.com/obay/hscli/internal/hubspot"
+
+// lineItemsKind describes the line-items command tree
+var lineItemsKind = objectKind{
+	ObjectType: hubspot.LineItems,
+	command:    "line-items",
+	singular:   "line item",
+	plural:     "line items",
+	fields: []fieldFlag{
+		{name: "name", shorthand: "n", property: "name", usage: "Line item name"},
+		{name: "quantity", property: "quantity", usage: "Quantity"},
+		{name: "price", property: "price", usage: "Unit price"},
+		{name: "product-id", property: "hs_product_id", usage: "ID of the product this line item is based on"},
+	},
+	associationTargets: []string{"deals"},
+	columns: []column{
+		{header: "Name", property: "name"},
+		{header: "Quantity", property: "quantity"},
+		{header: "Price", property: "price"},
+		{header: "Amount", property: "amount"},
+		{header: "Product ID", property: "hs_product_id"},
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newObjectCmd(lineItemsKind))
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/obay/hscli/hubspottest"
+	"github.com/spf13/cobra"
+)
+
+var mockCmd = &cobra.Command{
+	Use:   "mock",
+	Short: "Run an offline mock of the HubSpot API",
+}
+
+var mockServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve an in-memory mock of the HubSpot CRM API",
+	Long: `Serve an in-memory mock of the HubSpot CRM API for testing scripts
+without a real portal.
+
+The mock implements CRUD, batch, search, properties and pagination for the
+standard object types and answers with HubSpot's error payloads. Data is
+kept in memory and lost when the server stops.
+
+Point hscli at it with --base-url or HUBSPOT_BASE_URL:
+
+  hscli mock serve --port 8080 --seed 50 &
+  export HUBSPOT_BASE_URL=http://localhost:8080 HUBSPOT_API_KEY=mock
+  hscli contacts list`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		port, _ := cmd.Flags().GetInt("port")
+		seed, _ := cmd.Flags().GetInt("seed")
+
+		mock := hubspottest.New()
+		mock.APIKey, _ = cmd.Flags().GetString("token")
+		mock.RateLimit, _ = cmd.Flags().GetInt("rate-limit")
+		mock.Seed(seed)
+
+		listener, err := net.Listen("tcp", net.JoinHostPort("localhost", strconv.Itoa(port)))
+		if err != nil {
+			return fmt.Errorf("failed to start mock server: %w", err)
+		}
+
+		server := &http.Server{Handler: mock}
+		go func() {
+			<-cmd.Context().Done()
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			server.Shutdown(ctx)
+		}()
+
+		_, actualPort, _ := net.SplitHostPort(listener.Addr().String())
+		fmt.Fprintf(os.Stderr, "Mock HubSpot API listening on http://localhost:%s (Ctrl-C to stop)\n", actualPort)
+
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("mock server failed: %w", err)
+		}
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mockCmd)
+	mockCmd.AddCommand(mockServeCmd)
+
+	mockServeCmd.Flags().Int("port", 8080, "Port to listen on (0 picks a free port)")
+	mockServeCmd.Flags().Int("seed", 0, "Number of sample contacts to create on startup")
+	mockServeCmd.Flags().Int("rate-limit", 0, "Requests allowed per second before answering 429 (0 disables)")
+	mockServeCmd.Flags().String("token", "", "Only accept this bearer token (default: accept any)")
+}
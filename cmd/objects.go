@@ -0,0 +1,626 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/output"
+	"github.com/obay/hscli/internal/query"
+	"github.com/spf13/cobra"
+)
+
+// objectKind describes a CRM object type exposed as a command tree
+type objectKind struct {
+	hubspot.ObjectType
+	command  string      // command name, e.g. "line-items"
+	singular string      // singular noun used in messages, e.g. "line item"
+	plural   string      // plural noun used in messages, e.g. "line items"
+	fields   []fieldFlag // convenience flags for create and update
+	columns  []column    // table columns after the ID
+
+	// columnsChosen is set when --columns replaced the table columns,
+	// which then include the ID column only where it was listed
+	columnsChosen bool
+
+	// associationTargets are the object types listed by "associations" without --to
+	associationTargets []string
+
+	// modifiedProperty holds the last modification date of the types that
+	// hscli sync mirrors; their read commands accept --offline
+	modifiedProperty string
+
+	// resolve, if set, looks up the actual kind before any subcommand runs,
+	// e.g. for custom objects whose schema is only known at runtime
+	resolve func(cmd *cobra.Command) (objectKind, error)
+}
+
+// fieldFlag maps a convenience flag to a HubSpot property
+type fieldFlag struct {
+	name      string
+	shorthand string
+	property  string
+	usage     string
+}
+
+// column describes a table column bound to a property
+type column struct {
+	header   string
+	property string
+}
+
+// idColumn is the leading table column of an object
+var idColumn = column{header: "ID", property: "id"}
+
+// value returns the column's value for object. Besides properties, columns
+// may name the id, createdAt, updatedAt and archived fields.
+func (c column) value(object hubspot.Object) string {
+	switch c.property {
+	case "id":
+		return object.ID
+	case "createdAt":
+		return object.CreatedAt
+	case "updatedAt":
+		return object.UpdatedAt
+	case "archived":
+		return strconv.FormatBool(object.Archived)
+	}
+	return getStringValue(object.Properties[c.property])
+}
+
+// tableColumns returns the columns of table, CSV and TSV output
+func (k objectKind) tableColumns() []column {
+	if k.columnsChosen {
+		return k.columns
+	}
+	return append([]column{idColumn}, k.columns...)
+}
+
+// noun returns the singular or plural noun for n objects
+func (k objectKind) noun(n int) string {
+	if n == 1 {
+		return k.singular
+	}
+	return k.plural
+}
+
+// newObjectCmd builds the list/get/create/update/delete/query/properties command tree for an object kind
+func newObjectCmd(kind objectKind) *cobra.Command {
+	idArg := fmt.Sprintf("[%s-id]", strings.ReplaceAll(kind.singular, " ", "-"))
+
+	parent := &cobra.Command{
+		Use:   kind.command,
+		Short: fmt.Sprintf("Manage HubSpot %s", kind.plural),
+		Long:  fmt.Sprintf(`Manage HubSpot %s with CRUD operations.`, kind.plural),
+	}
+	if kind.resolve != nil {
+		// The subcommands below capture kind, so they all see the resolved value
+		parent.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+			resolved, err := kind.resolve(cmd)
+			if err != nil {
+				return err
+			}
+			kind = resolved
+			return nil
+		}
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: fmt.Sprintf("List all %s", kind.plural),
+		Long:  fmt.Sprintf(`List all %s in HubSpot with their properties.`, kind.plural),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			if limit == 0 {
+				limit = 100
+			}
+			showAll, _ := cmd.Flags().GetBool("all")
+
+			if isOffline(cmd) {
+				return listOffline(cmd, kind, limit, showAll)
+			}
+
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties, view, err := selectProperties(cmd, client, kind)
+			if err != nil {
+				return err
+			}
+
+			w, err := objectWriterFor(cmd, view)
+			if err != nil {
+				return err
+			}
+
+			it := client.ListIterator(cmd.Context(), kind.Name, limit, properties)
+			return streamObjects(cmd, it, w, showAll, "list "+kind.plural)
+		},
+	}
+	listCmd.Flags().IntP("limit", "l", 100, fmt.Sprintf("Maximum number of %s to retrieve", kind.plural))
+	listCmd.Flags().BoolP("all", "a", false, fmt.Sprintf("Retrieve all %s (paginate through all pages)", kind.plural))
+	addObjectOutputFlags(listCmd)
+	addPropertySelectionFlags(listCmd)
+	addOfflineFlag(listCmd, kind)
+
+	getCmd := &cobra.Command{
+		Use:   "get " + idArg,
+		Short: fmt.Sprintf("Get a %s", kind.singular),
+		Long:  fmt.Sprintf(`Get a single %s from HubSpot by ID.`, kind.singular),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if isOffline(cmd) {
+				return getOffline(cmd, kind, args[0])
+			}
+
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties, view, err := selectProperties(cmd, client, kind)
+			if err != nil {
+				return err
+			}
+
+			var associations []string
+			targets, _ := cmd.Flags().GetStringSlice("associations")
+			for _, target := range targets {
+				toType, err := resolveTypeName(cmd, client, target)
+				if err != nil {
+					return err
+				}
+				associations = append(associations, toType)
+			}
+
+			object, err := client.GetObjectWithAssociations(cmd.Context(), kind.Name, args[0], properties, associations)
+			if err != nil {
+				return fmt.Errorf("failed to get %s: %w", kind.singular, err)
+			}
+
+			w, err := objectWriterFor(cmd, view)
+			if err != nil {
+				return err
+			}
+			if err := w.Write(*object); err != nil {
+				return err
+			}
+			if err := w.Close(); err != nil {
+				return err
+			}
+			if _, table := w.(*tableObjectWriter); table && len(object.Associations) > 0 {
+				fmt.Println()
+				printAssociatedIDs(*object)
+			}
+			return nil
+		},
+	}
+	addObjectOutputFlags(getCmd)
+	addPropertySelectionFlags(getCmd)
+	getCmd.Flags().StringSlice("associations", nil, "Include the IDs of associated objects of these types (e.g. companies,deals)")
+	addOfflineFlag(getCmd, kind)
+	if kind.modifiedProperty != "" {
+		getCmd.MarkFlagsMutuallyExclusive("offline", "associations")
+	}
+
+	createCmd := &cobra.Command{
+		Use:   "create",
+		Short: fmt.Sprintf("Create a new %s", kind.singular),
+		Long: fmt.Sprintf(`Create a new %s in HubSpot.
+
+Set any property with --set key=value, e.g. --set phone=555-1234; repeat it
+or separate pairs with commas. Unlike on read commands, where --properties
+lists the properties to retrieve, values are assigned here, so -p/--properties
+is only kept as a deprecated alias of --set.`, kind.singular),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties := propertiesFromFlags(cmd, kind)
+			if len(properties) == 0 {
+				return fmt.Errorf("at least one property is required to create a %s", kind.singular)
+			}
+
+			object, err := client.CreateObject(cmd.Context(), kind.Name, properties)
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", kind.singular, err)
+			}
+
+			fmt.Printf("%s created successfully:\n", capitalize(kind.singular))
+			return printObjects(kind, []hubspot.Object{*object}, output.Table)
+		},
+	}
+	addFieldFlags(createCmd, kind)
+
+	updateCmd := &cobra.Command{
+		Use:   "update " + idArg,
+		Short: fmt.Sprintf("Update a %s", kind.singular),
+		Long: fmt.Sprintf(`Update a %s's properties.
+
+Set any property with --set key=value, e.g. --set phone=555-1234; repeat it
+or separate pairs with commas. Unlike on read commands, where --properties
+lists the properties to retrieve, values are assigned here, so -p/--properties
+is only kept as a deprecated alias of --set.`, kind.singular),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties := propertiesFromFlags(cmd, kind)
+			if len(properties) == 0 {
+				return fmt.Errorf("at least one property is required to update a %s", kind.singular)
+			}
+
+			object, err := client.UpdateObject(cmd.Context(), kind.Name, args[0], properties)
+			if err != nil {
+				return fmt.Errorf("failed to update %s: %w", kind.singular, err)
+			}
+
+			fmt.Printf("%s updated successfully:\n", capitalize(kind.singular))
+			return printObjects(kind, []hubspot.Object{*object}, output.Table)
+		},
+	}
+	addFieldFlags(updateCmd, kind)
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete " + idArg,
+		Short: fmt.Sprintf("Delete a %s", kind.singular),
+		Long:  fmt.Sprintf(`Delete a %s from HubSpot by ID.`, kind.singular),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			objectID := args[0]
+
+			force, _ := cmd.Flags().GetBool("force")
+			if !force {
+				object, err := client.GetObject(cmd.Context(), kind.Name, objectID, kind.DefaultProperties)
+				if err != nil {
+					return fmt.Errorf("failed to get %s: %w", kind.singular, err)
+				}
+
+				// Identify the object by its first display column, e.g. the contact's email
+				description := ""
+				if len(kind.columns) > 0 {
+					label := kind.columns[0]
+					value := getStringValue(object.Properties[label.property])
+					if value == "" {
+						value = "N/A"
+					}
+					description = fmt.Sprintf(" (%s: %s)", strings.ToLower(label.header), value)
+				}
+
+				fmt.Printf("Are you sure you want to delete %s %s%s? [y/N]: ", kind.singular, objectID, description)
+				var response string
+				fmt.Scanln(&response)
+				if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+					fmt.Println("Deletion cancelled.")
+					return nil
+				}
+			}
+
+			err = client.DeleteObject(cmd.Context(), kind.Name, objectID)
+			if err != nil {
+				return fmt.Errorf("failed to delete %s: %w", kind.singular, err)
+			}
+
+			fmt.Printf("%s %s deleted successfully.\n", capitalize(kind.singular), objectID)
+			return nil
+		},
+	}
+	deleteCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+
+	queryCmd := &cobra.Command{
+		Use:   "query [search-query]",
+		Short: fmt.Sprintf("Search for %s", kind.plural),
+		Long: fmt.Sprintf(`Search for %s using HubSpot's search API.
+
+The query is a filter expression combining comparisons with AND, OR, NOT
+and parentheses:
+
+  lifecyclestage = lead AND createdate >= 2025-01-01
+  company ~ acme OR (email HAS_PROPERTY AND NOT hs_lead_status IN (NEW, OPEN))
+
+Operators: = != < <= > >= ~ (contains token) !~ IN (...) NOT IN (...)
+BETWEEN x AND y, HAS_PROPERTY, NOT HAS_PROPERTY. Quote values containing
+spaces or operator characters. Unquoted dates such as 2025-01-01 are sent
+as Unix milliseconds.
+
+A bare term without operators matches the %s property with
+CONTAINS_TOKEN.
+
+--text runs HubSpot's free-text search across the default searchable
+properties and can be combined with a filter expression. --sort orders
+the results, e.g. --sort createdate:desc --sort lastname.%s`, kind.plural, searchPropertyName(kind), offlineQueryHelp(kind)),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			text, _ := cmd.Flags().GetString("text")
+			aggregating := cmd.Flags().Changed("group-by") || cmd.Flags().Changed("agg")
+			if aggregating && !isOffline(cmd) {
+				return fmt.Errorf("--group-by and --agg only work with --offline")
+			}
+			if len(args) == 0 && text == "" && !aggregating {
+				return fmt.Errorf("a search query or --text is required")
+			}
+
+			sortSpecs, _ := cmd.Flags().GetStringSlice("sort")
+			sorts, err := query.ParseSorts(sortSpecs)
+			if err != nil {
+				return err
+			}
+
+			limit, _ := cmd.Flags().GetInt("limit")
+			if limit == 0 {
+				limit = 100
+			}
+
+			showAll, _ := cmd.Flags().GetBool("all")
+
+			if isOffline(cmd) {
+				input := ""
+				if len(args) == 1 {
+					input = args[0]
+				}
+				return queryOffline(cmd, kind, input, text, sorts, limit, showAll)
+			}
+
+			filterGroups := []hubspot.FilterGroup{}
+			if len(args) == 1 {
+				if filterGroups, err = compileQuery(args[0], kind); err != nil {
+					return err
+				}
+			}
+
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties, view, err := selectProperties(cmd, client, kind)
+			if err != nil {
+				return err
+			}
+
+			w, err := objectWriterFor(cmd, view)
+			if err != nil {
+				return err
+			}
+
+			it := client.SearchIterator(cmd.Context(), kind.Name, hubspot.SearchRequest{
+				FilterGroups: filterGroups,
+				Sorts:        sorts,
+				Query:        text,
+				Limit:        limit,
+				Properties:   properties,
+			})
+			return streamObjects(cmd, it, w, showAll, "search "+kind.plural)
+		},
+	}
+	queryCmd.Flags().IntP("limit", "l", 100, "Maximum number of results per page")
+	queryCmd.Flags().BoolP("all", "a", false, "Retrieve all results (paginate through all pages)")
+	addObjectOutputFlags(queryCmd)
+	queryCmd.Flags().StringSlice("sort", nil, "Sort by a property as property[:asc|desc] (repeatable)")
+	queryCmd.Flags().String("text", "", "Free-text search across the default searchable properties")
+	addPropertySelectionFlags(queryCmd)
+	addOfflineFlag(queryCmd, kind)
+	if kind.modifiedProperty != "" {
+		queryCmd.Flags().StringSlice("group-by", nil, "With --offline, group the results by these properties")
+		queryCmd.Flags().StringSlice("agg", nil, "With --offline, aggregate each group as count, count:prop, distinct:prop, sum:prop, avg:prop, min:prop or max:prop (repeatable)")
+	}
+
+	propertiesCmd := &cobra.Command{
+		Use:   "properties",
+		Short: fmt.Sprintf("List all %s properties", kind.singular),
+		Long:  fmt.Sprintf(`List all available properties for %s in HubSpot.`, kind.plural),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties, err := client.ListObjectProperties(cmd.Context(), kind.Name)
+			if err != nil {
+				return fmt.Errorf("failed to list properties: %w", err)
+			}
+
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			return printProperties(properties, format)
+		},
+	}
+	propertiesCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+
+	historyCmd := &cobra.Command{
+		Use:   "history " + idArg,
+		Short: fmt.Sprintf("Show the property history of a %s", kind.singular),
+		Long: fmt.Sprintf(`Show a chronological timeline of property value changes of a %s,
+including when each change happened and its source.
+
+Without --property, the history of the default properties is shown.`, kind.singular),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := newClient()
+			if err != nil {
+				return err
+			}
+
+			properties, _ := cmd.Flags().GetStringSlice("property")
+			if len(properties) == 0 {
+				properties = kind.DefaultProperties
+			}
+
+			object, err := client.GetObjectHistory(cmd.Context(), kind.Name, args[0], properties)
+			if err != nil {
+				return fmt.Errorf("failed to get %s history: %w", kind.singular, err)
+			}
+
+			format, err := outputFormat(cmd)
+			if err != nil {
+				return err
+			}
+			return printHistory(object.Timeline(), format)
+		},
+	}
+	historyCmd.Flags().StringSlice("property", nil, "Property to show the history of (repeatable or comma-separated)")
+	historyCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+
+	parent.AddCommand(listCmd, getCmd, createCmd, updateCmd, deleteCmd, queryCmd, propertiesCmd, historyCmd)
+	addAssociationCmds(parent, &kind, idArg)
+	addExportCmd(parent, &kind)
+	return parent
+}
+
+// addFieldFlags registers the convenience property flags and the generic --set flag
+func addFieldFlags(cmd *cobra.Command, kind objectKind) {
+	for _, field := range kind.fields {
+		cmd.Flags().StringP(field.name, field.shorthand, "", field.usage)
+	}
+	cmd.Flags().StringArray("set", nil, "Property to set as key=value (repeatable, or comma-separated as key1=value1,key2=value2)")
+	// --properties lists the properties to retrieve on read commands
+	cmd.Flags().StringP("properties", "p", "", "Additional properties (format: key1=value1,key2=value2)")
+	cmd.Flags().MarkDeprecated("properties", "use --set instead")
+}
+
+// propertiesFromFlags collects the properties set through convenience flags, --set and --properties
+func propertiesFromFlags(cmd *cobra.Command, kind objectKind) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for _, field := range kind.fields {
+		if value, _ := cmd.Flags().GetString(field.name); value != "" {
+			properties[field.property] = value
+		}
+	}
+
+	// Parse additional properties from strings (format: "key1=value1,key2=value2")
+	assignments, _ := cmd.Flags().GetStringArray("set")
+	if legacy, _ := cmd.Flags().GetString("properties"); legacy != "" {
+		assignments = append([]string{legacy}, assignments...)
+	}
+	for _, assignment := range assignments {
+		pairs := strings.Split(assignment, ",")
+		for _, pair := range pairs {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) == 2 {
+				key := strings.TrimSpace(parts[0])
+				value := strings.TrimSpace(parts[1])
+				properties[key] = value
+			}
+		}
+	}
+
+	return properties
+}
+
+// streamObjects writes the iterator's objects to w as they are fetched,
+// stopping after the first page unless all is set
+func streamObjects(cmd *cobra.Command, it *hubspot.Iterator, w objectWriter, all bool, action string) error {
+	count := 0
+	for it.Next() {
+		if err := w.Write(it.Object()); err != nil {
+			return err
+		}
+		count++
+		if !all && it.PageDone() {
+			break
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		if count > 0 {
+			// Terminate what was already written, e.g. close the JSON array
+			w.Close()
+		}
+		return interrupted(cmd, fmt.Errorf("failed to %s: %w", action, err), count)
+	}
+	return w.Close()
+}
+
+func printObjects(kind objectKind, objects []hubspot.Object, format string) error {
+	w := newObjectWriter(os.Stdout, kind, format)
+	for _, object := range objects {
+		if err := w.Write(object); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+func printHistory(changes []hubspot.PropertyChange, format string) error {
+	headers := []string{"timestamp", "property", "value", "sourceType", "sourceId"}
+	if ok, err := printEncoded(format, changes, headers, func(change hubspot.PropertyChange) []string {
+		return []string{change.Timestamp, change.Property, change.Value, change.SourceType, change.SourceID}
+	}); ok {
+		return err
+	}
+
+	// Table format
+	table := newTable(os.Stdout, "Timestamp", "Property", "Value", "Source Type", "Source ID")
+	for _, change := range changes {
+		if err := table.Append(change.Timestamp, change.Property, change.Value, change.SourceType, change.SourceID); err != nil {
+			return err
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return printTotal(os.Stdout, len(changes), "change(s)")
+}
+
+// compileQuery turns a query argument into filter groups. Bare terms keep
+// the original behavior of matching the kind's search property.
+func compileQuery(input string, kind objectKind) ([]hubspot.FilterGroup, error) {
+	if !query.IsExpression(input) {
+		return hubspot.SimpleQuery(input, kind.SearchProperty), nil
+	}
+
+	groups, err := query.Compile(input)
+	if err != nil {
+		return nil, fmt.Errorf("invalid query: %w", err)
+	}
+	return groups, nil
+}
+
+// offlineQueryHelp describes --offline for the query help of mirrored kinds
+func offlineQueryHelp(kind objectKind) string {
+	if kind.modifiedProperty == "" {
+		return ""
+	}
+	return `
+
+--offline evaluates the query against the local mirror kept by hscli sync,
+without calling HubSpot or its search limits. Any mirrored property can be
+filtered, and MATCHES / NOT MATCHES compare values with a regular
+expression, e.g. email MATCHES '@(gmail|yahoo)\.com$'. --text only looks at
+the searchable properties that are mirrored.
+
+--group-by and --agg summarize the matching objects offline instead of
+listing them, e.g. --group-by lifecyclestage --agg count --agg
+distinct:company. Without --agg each group is counted.`
+}
+
+// searchPropertyName names the property bare search terms are matched against
+func searchPropertyName(kind objectKind) string {
+	if kind.SearchProperty == "" {
+		return "primary display"
+	}
+	return kind.SearchProperty
+}
+
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,201 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/output"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// objectWriter renders objects one at a time, so paginated results can be
+// streamed to stdout as they arrive
+type objectWriter interface {
+	Write(object hubspot.Object) error
+	// Close writes any trailing output, such as the table total
+	Close() error
+}
+
+// addObjectOutputFlags registers the flags that choose how object read commands render results
+func addObjectOutputFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("format", "f", output.Table, output.Usage)
+	cmd.Flags().String("output", "", "Render each object with a Go template, as template=TEXT or template-file=PATH")
+	cmd.MarkFlagsMutuallyExclusive("format", "output")
+}
+
+// objectWriterFor returns the writer chosen by the --output and --format flags
+func objectWriterFor(cmd *cobra.Command, kind objectKind) (objectWriter, error) {
+	if spec, _ := cmd.Flags().GetString("output"); spec != "" {
+		tmpl, err := parseOutputTemplate(spec)
+		if err != nil {
+			return nil, err
+		}
+		return &templateObjectWriter{w: os.Stdout, tmpl: tmpl}, nil
+	}
+
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return nil, err
+	}
+	return newObjectWriter(os.Stdout, kind, format), nil
+}
+
+// parseOutputTemplate parses an --output value of the form template=TEXT or template-file=PATH
+func parseOutputTemplate(spec string) (*template.Template, error) {
+	kind, value, _ := strings.Cut(spec, "=")
+	switch kind {
+	case "template":
+		return output.ParseTemplate(value)
+	case "template-file":
+		text, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template file: %w", err)
+		}
+		return output.ParseTemplate(string(text))
+	}
+	return nil, fmt.Errorf("invalid --output %q, expected template=TEXT or template-file=PATH", spec)
+}
+
+// newObjectWriter returns a writer for the given output format
+func newObjectWriter(w io.Writer, kind objectKind, format string) objectWriter {
+	if format == output.Table {
+		var headers []string
+		for _, col := range kind.tableColumns() {
+			headers = append(headers, col.header)
+		}
+		return &tableObjectWriter{w: w, kind: kind, table: newTable(w, headers...)}
+	}
+
+	var headers []string
+	for _, col := range kind.tableColumns() {
+		headers = append(headers, col.property)
+	}
+	return &encodedObjectWriter{enc: output.NewEncoder(w, format, headers), kind: kind}
+}
+
+// encodedObjectWriter streams objects in one of the machine-readable
+// formats. CSV and TSV columns follow the table columns.
+type encodedObjectWriter struct {
+	enc  *output.Encoder
+	kind objectKind
+}
+
+func (ew *encodedObjectWriter) Write(object hubspot.Object) error {
+	var row []string
+	for _, col := range ew.kind.tableColumns() {
+		row = append(row, col.value(object))
+	}
+	return ew.enc.Encode(object, row)
+}
+
+func (ew *encodedObjectWriter) Close() error {
+	return ew.enc.Close()
+}
+
+// templateObjectWriter executes a template for each object, ending each
+// result with a newline unless the template already does
+type templateObjectWriter struct {
+	w    io.Writer
+	tmpl *template.Template
+	buf  bytes.Buffer
+}
+
+func (tw *templateObjectWriter) Write(object hubspot.Object) error {
+	tw.buf.Reset()
+	if err := tw.tmpl.Execute(&tw.buf, object); err != nil {
+		return fmt.Errorf("failed to execute output template: %w", err)
+	}
+	if !bytes.HasSuffix(tw.buf.Bytes(), []byte("\n")) {
+		tw.buf.WriteByte('\n')
+	}
+	_, err := tw.w.Write(tw.buf.Bytes())
+	return err
+}
+
+func (tw *templateObjectWriter) Close() error {
+	return nil
+}
+
+// printEncoded writes items in a machine-readable format, with row giving
+// the CSV and TSV columns named by headers. It reports false for the table
+// format, which callers render themselves.
+func printEncoded[T any](format string, items []T, headers []string, row func(T) []string) (bool, error) {
+	if format == output.Table {
+		return false, nil
+	}
+
+	enc := output.NewEncoder(os.Stdout, format, headers)
+	for _, item := range items {
+		if err := enc.Encode(item, row(item)); err != nil {
+			return true, err
+		}
+	}
+	return true, enc.Close()
+}
+
+// tableObjectWriter prints objects as table rows
+type tableObjectWriter struct {
+	w     io.Writer
+	kind  objectKind
+	table *output.TableWriter
+	count int
+}
+
+func (tw *tableObjectWriter) Write(object hubspot.Object) error {
+	var row []string
+	for _, col := range tw.kind.tableColumns() {
+		row = append(row, col.value(object))
+	}
+	tw.count++
+	return tw.table.Append(row...)
+}
+
+func (tw *tableObjectWriter) Close() error {
+	if err := tw.table.Flush(); err != nil {
+		return err
+	}
+	return printTotal(tw.w, tw.count, tw.kind.noun(tw.count))
+}
+
+// newTable returns a table writer laid out for the terminal and the
+// --wrap and --no-headers flags
+func newTable(w io.Writer, headers ...string) *output.TableWriter {
+	wrap, _ := rootCmd.PersistentFlags().GetBool("wrap")
+	noHeaders, _ := rootCmd.PersistentFlags().GetBool("no-headers")
+	return output.NewTableWriter(w, headers, output.TableOptions{
+		Width:      terminalWidth(w),
+		Wrap:       wrap,
+		NoHeaders:  noHeaders,
+		SampleRows: output.DefaultSampleRows,
+	})
+}
+
+// printTotal writes the count line below a table, which --no-headers leaves out
+func printTotal(w io.Writer, n int, noun string) error {
+	if noHeaders, _ := rootCmd.PersistentFlags().GetBool("no-headers"); noHeaders {
+		return nil
+	}
+	_, err := fmt.Fprintf(w, "\nTotal: %d %s\n", n, noun)
+	return err
+}
+
+// terminalWidth returns the width tables are fitted to: the terminal's
+// width, COLUMNS when set, or 0 for no limit when output is redirected
+func terminalWidth(w io.Writer) int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if f, ok := w.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		if width, _, err := term.GetSize(int(f.Fd())); err == nil {
+			return width
+		}
+	}
+	return 0
+}
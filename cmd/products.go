@@ -0,0 +1,27 @@
+package cmd
+
+import "github.com/obay/hscli/internal/hubspot"
+
+// productsKind describes the products command tree
+var productsKind = objectKind{
+	ObjectType: hubspot.Products,
+	command:    "products",
+	singular:   "product",
+	plural:     "products",
+	fields: []fieldFlag{
+		{name: "name", shorthand: "n", property: "name", usage: "Product name"},
+		{name: "price", property: "price", usage: "Unit price"},
+		{name: "sku", property: "hs_sku", usage: "SKU"},
+		{name: "description", property: "description", usage: "Product description"},
+	},
+	columns: []column{
+		{header: "Name", property: "name"},
+		{header: "Price", property: "price"},
+		{header: "SKU", property: "hs_sku"},
+		{header: "Description", property: "description"},
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newObjectCmd(productsKind))
+}
@@ -0,0 +1,357 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/obay/hscli/internal/output"
+	"github.com/obay/hscli/internal/properties"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	// activeProfile is the name of the profile applied by initConfig, if any
+	activeProfile string
+	// profileSource describes where the active profile was selected
+	profileSource string
+	// profileErr is reported by newClient when the selected profile does not exist
+	profileErr error
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage config profiles for multiple HubSpot portals",
+	Long: `Manage named profiles stored under profiles in the config file.
+
+A profile can set its own api-key, base-url, format and default-properties,
+which take precedence over the top-level settings of the config file. The
+profile is chosen with --profile, the HUBSPOT_PROFILE env var or
+hscli profile use, in that order.
+
+Example config:
+
+  current-profile: production
+  profiles:
+    production:
+      api-key: pat-na1-...
+      default-properties:
+        contacts: email,firstname,lastname,@sales
+    sandbox:
+      api-key: pat-na1-...
+      format: json`,
+}
+
+var listProfilesCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	Long:  `List the profiles in the config file. The active profile is marked with an asterisk.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+
+		var profiles []profileInfo
+		for _, name := range profileNames() {
+			profiles = append(profiles, profileInfo{
+				Name:    name,
+				Active:  name == activeProfile,
+				BaseURL: viper.GetString("profiles." + name + ".base-url"),
+				Format:  viper.GetString("profiles." + name + ".format"),
+			})
+		}
+
+		headers := []string{"name", "active", "baseUrl", "format"}
+		if ok, err := printEncoded(format, profiles, headers, func(p profileInfo) []string {
+			return []string{p.Name, strconv.FormatBool(p.Active), p.BaseURL, p.Format}
+		}); ok {
+			return err
+		}
+
+		// Table format
+		if len(profiles) == 0 {
+			fmt.Println("No profiles configured. Add one with hscli profile add.")
+			return nil
+		}
+		table := newTable(os.Stdout, "Active", "Name", "Base URL", "Format")
+		for _, p := range profiles {
+			marker := ""
+			if p.Active {
+				marker = "*"
+			}
+			if err := table.Append(marker, p.Name, orDefault(p.BaseURL), orDefault(p.Format)); err != nil {
+				return err
+			}
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+		return printTotal(os.Stdout, len(profiles), "profile(s)")
+	},
+}
+
+// profileInfo describes a profile for profile list
+type profileInfo struct {
+	Name    string `json:"name"`
+	Active  bool   `json:"active"`
+	BaseURL string `json:"baseUrl,omitempty"`
+	Format  string `json:"format,omitempty"`
+}
+
+// orDefault shows unset profile settings as (default)
+func orDefault(value string) string {
+	if value == "" {
+		return "(default)"
+	}
+	return value
+}
+
+var currentProfileCmd = &cobra.Command{
+	Use:   "current",
+	Short: "Show the active profile",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if activeProfile == "" {
+			fmt.Println("No profile is active; the top-level settings of the config file are used.")
+			return nil
+		}
+		if profileErr != nil {
+			return profileErr
+		}
+
+		fmt.Printf("%s (selected by %s)\n", activeProfile, profileSource)
+		return nil
+	},
+}
+
+var useProfileCmd = &cobra.Command{
+	Use:   "use [name]",
+	Short: "Set the default profile",
+	Long:  `Set the profile used when neither --profile nor HUBSPOT_PROFILE is given.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if !profileExists(name) {
+			return fmt.Errorf("profile %q not found; run hscli profile list", args[0])
+		}
+
+		err := editConfig(func(settings map[string]interface{}) error {
+			settings["current-profile"] = name
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Now using profile %s.\n", name)
+		return nil
+	},
+}
+
+var addProfileCmd = &cobra.Command{
+	Use:   "add [name]",
+	Short: "Add a profile",
+	Long: `Add a profile to the config file.
+
+Default properties are given per object type, e.g.
+--default-properties "contacts=email,firstname,@sales".`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if profileExists(name) {
+			return fmt.Errorf("profile %q already exists; remove it first", name)
+		}
+
+		profile := make(map[string]interface{})
+		for _, key := range []string{"api-key", "base-url", "format"} {
+			if value, _ := cmd.Flags().GetString(key); value != "" {
+				profile[key] = value
+			}
+		}
+
+		defaults, _ := cmd.Flags().GetStringArray("default-properties")
+		if len(defaults) > 0 {
+			byType, err := properties.ParseDefaults(defaults)
+			if err != nil {
+				return err
+			}
+			profile["default-properties"] = byType
+		}
+
+		use, _ := cmd.Flags().GetBool("use")
+		err := editConfig(func(settings map[string]interface{}) error {
+			profiles, _ := settings["profiles"].(map[string]interface{})
+			if profiles == nil {
+				profiles = make(map[string]interface{})
+			}
+			profiles[name] = profile
+			settings["profiles"] = profiles
+			if use {
+				settings["current-profile"] = name
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Profile %s added to %s.\n", name, configFilePath())
+		return nil
+	},
+}
+
+var removeProfileCmd = &cobra.Command{
+	Use:   "remove [name]",
+	Short: "Remove a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := strings.ToLower(args[0])
+		if !profileExists(name) {
+			return fmt.Errorf("profile %q not found; run hscli profile list", args[0])
+		}
+
+		err := editConfig(func(settings map[string]interface{}) error {
+			profiles, _ := settings["profiles"].(map[string]interface{})
+			delete(profiles, name)
+			if current, _ := settings["current-profile"].(string); current == name {
+				delete(settings, "current-profile")
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Profile %s removed.\n", name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(profileCmd)
+
+	profileCmd.AddCommand(listProfilesCmd, currentProfileCmd, useProfileCmd, addProfileCmd, removeProfileCmd)
+
+	listProfilesCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+
+	addProfileCmd.Flags().String("api-key", "", "HubSpot API key for the profile")
+	addProfileCmd.Flags().String("base-url", "", "API base URL for the profile")
+	addProfileCmd.Flags().String("format", "", "Default output format for the profile")
+	addProfileCmd.Flags().StringArray("default-properties", nil, "Default properties for an object type as type=prop1,prop2 (repeatable)")
+	addProfileCmd.Flags().Bool("use", false, "Make the new profile the default")
+}
+
+// applyProfile selects the active profile and layers its settings over the
+// top-level settings of the config file. Flags and env vars still win.
+func applyProfile() {
+	activeProfile, profileSource, profileErr = "", "", nil
+
+	name := viper.GetString("profile")
+	switch {
+	case name != "" && rootCmd.PersistentFlags().Changed("profile"):
+		profileSource = "--profile flag"
+	case name != "":
+		profileSource = "HUBSPOT_PROFILE env var"
+	default:
+		name = viper.GetString("current-profile")
+		profileSource = "current-profile in " + configFilePath()
+	}
+	if name == "" {
+		profileSource = ""
+		return
+	}
+
+	activeProfile = strings.ToLower(name)
+	if !profileExists(activeProfile) {
+		profileErr = fmt.Errorf("profile %q not found; run hscli profile list", name)
+		return
+	}
+
+	if err := viper.MergeConfigMap(viper.GetStringMap("profiles." + activeProfile)); err != nil {
+		profileErr = fmt.Errorf("failed to apply profile %q: %w", name, err)
+	}
+}
+
+// profileNames returns the configured profile names in sorted order
+func profileNames() []string {
+	var names []string
+	for name := range viper.GetStringMap("profiles") {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func profileExists(name string) bool {
+	_, ok := viper.GetStringMap("profiles")[name]
+	return ok
+}
+
+// outputFormat returns the --format flag, falling back to the format set in
+// the config file or active profile when the flag was not given
+func outputFormat(cmd *cobra.Command) (string, error) {
+	format, _ := cmd.Flags().GetString("format")
+	if !cmd.Flags().Changed("format") {
+		if configured := viper.GetString("format"); configured != "" {
+			format = configured
+		}
+	}
+	if err := output.Validate(format); err != nil {
+		return "", err
+	}
+	return format, nil
+}
+
+// configFilePath returns the config file hscli reads and writes
+func configFilePath() string {
+	if cfgFile != "" {
+		return cfgFile
+	}
+	if used := viper.ConfigFileUsed(); used != "" {
+		return used
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ".hscli.yaml"
+	}
+	return filepath.Join(home, ".hscli.yaml")
+}
+
+// editConfig rewrites the config file with the changes made by edit. Only the
+// file's own settings are written, never values from flags or env vars.
+func editConfig(edit func(settings map[string]interface{}) error) error {
+	path := configFilePath()
+	configType := strings.TrimPrefix(filepath.Ext(path), ".")
+	if configType == "" {
+		configType = "yaml"
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	file.SetConfigType(configType)
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	settings := file.AllSettings()
+	if err := edit(settings); err != nil {
+		return err
+	}
+
+	updated := viper.New()
+	updated.SetConfigType(configType)
+	updated.SetConfigPermissions(0o600)
+	for key, value := range settings {
+		updated.Set(key, value)
+	}
+	if err := updated.WriteConfigAs(path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
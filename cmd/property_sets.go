@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/properties"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// addPropertySelectionFlags registers the flags that choose which properties read commands request
+func addPropertySelectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String("properties", "", "Comma-separated properties to retrieve; @name expands a property set from the config file")
+	cmd.Flags().Bool("all-properties", false, "Retrieve every property defined for the object type")
+	cmd.MarkFlagsMutuallyExclusive("properties", "all-properties")
+	cmd.Flags().StringSlice("columns", nil, "Comma-separated table, CSV and TSV columns, e.g. id,email,hubspot_owner_id; listed properties are retrieved too")
+}
+
+// selectProperties returns the properties a read command should request and
+// the kind to render them with. Explicitly listed properties become the
+// table columns; --all-properties keeps the default columns, since every
+// property is only practical to inspect in JSON. --columns replaces the
+// columns and adds the properties it names to the request.
+func selectProperties(cmd *cobra.Command, client *hubspot.Client, kind objectKind) ([]string, objectKind, error) {
+	names, kind, err := requestedProperties(cmd, client, kind)
+	if err != nil {
+		return nil, kind, err
+	}
+
+	columns, _ := cmd.Flags().GetStringSlice("columns")
+	if len(columns) == 0 {
+		return names, kind, nil
+	}
+
+	seen := make(map[string]bool)
+	for _, name := range names {
+		seen[name] = true
+	}
+	names = append([]string(nil), names...)
+
+	kind.columns, kind.columnsChosen = nil, true
+	for _, name := range columns {
+		name = strings.TrimSpace(name)
+		switch name {
+		case "":
+			continue
+		case idColumn.property:
+			kind.columns = append(kind.columns, idColumn)
+			continue
+		}
+
+		kind.columns = append(kind.columns, column{header: name, property: name})
+		// createdAt, updatedAt and archived are object fields, not properties
+		if name != "createdAt" && name != "updatedAt" && name != "archived" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	if len(kind.columns) == 0 {
+		return nil, kind, fmt.Errorf("no columns selected")
+	}
+	return names, kind, nil
+}
+
+// requestedProperties returns the properties chosen by --properties,
+// --all-properties or the configured defaults, and the kind to render them with
+func requestedProperties(cmd *cobra.Command, client *hubspot.Client, kind objectKind) ([]string, objectKind, error) {
+	if all, _ := cmd.Flags().GetBool("all-properties"); all {
+		definitions, err := client.ListObjectProperties(cmd.Context(), kind.Name)
+		if err != nil {
+			return nil, kind, fmt.Errorf("failed to list properties: %w", err)
+		}
+
+		names := make([]string, 0, len(definitions))
+		for _, prop := range definitions {
+			names = append(names, prop.Name)
+		}
+		return names, kind, nil
+	}
+
+	spec, _ := cmd.Flags().GetString("properties")
+	spec = properties.Resolve(spec, configuredDefaultProperties, kind.command, kind.Name)
+	if spec == "" {
+		return kind.DefaultProperties, kind, nil
+	}
+
+	names, err := properties.Sets(viper.GetStringMapStringSlice("property-sets")).Expand(spec)
+	if err != nil {
+		return nil, kind, err
+	}
+
+	kind.columns = nil
+	for _, name := range names {
+		kind.columns = append(kind.columns, column{header: name, property: name})
+	}
+	return names, kind, nil
+}
+
+// configuredDefaultProperties returns the properties set for an object type
+// under default-properties in the config file or active profile
+func configuredDefaultProperties(key string) []string {
+	return properties.Split(viper.Get("default-properties." + key))
+}
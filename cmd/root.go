@@ -1,28 +1,40 @@
 package cmd
 
 import (
+	"context"
 	"os"
+	"os/signal"
 
+	"github.com/obay/hscli/internal/hubspot"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-var cfgFile string
+var (
+	cfgFile    string
+	recordFile string
+	replayFile string
+)
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "hscli",
-	Short: "A CLI tool for managing HubSpot contacts",
-	Long: `hscli is a command-line tool for managing HubSpot contacts.
-It provides CRUD operations for contacts including listing, creating,
-updating, deleting, and querying contacts.`,
+	Short: "A CLI tool for managing HubSpot CRM objects",
+	Long: `hscli is a command-line tool for managing HubSpot CRM objects.
+It provides CRUD operations for contacts, companies, deals, tickets,
+products and line items including listing, creating, updating,
+deleting, and querying records.`,
 }
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 func Execute() {
-	err := rootCmd.Execute()
+	// Cancel in-flight requests on Ctrl-C so long-running commands stop cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
 	if err != nil {
-		os.Exit(1)
+		printErrorDetails(os.Stderr, err)
+		os.Exit(exitCode(err))
 	}
 }
 
@@ -31,7 +43,15 @@ func init() {
 
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.hscli.yaml)")
 	rootCmd.PersistentFlags().String("api-key", "", "HubSpot API key (or set HUBSPOT_API_KEY env var)")
+	rootCmd.PersistentFlags().String("profile", "", "Config profile to use (or set HUBSPOT_PROFILE env var)")
+	rootCmd.PersistentFlags().String("base-url", "", "HubSpot API base URL, e.g. of hscli mock serve (or set HUBSPOT_BASE_URL env var)")
+	rootCmd.PersistentFlags().StringVar(&recordFile, "record", "", "Record API requests and responses to a cassette file")
+	rootCmd.PersistentFlags().StringVar(&replayFile, "replay", "", "Replay API responses from a cassette file instead of calling HubSpot")
+	rootCmd.PersistentFlags().Bool("no-headers", false, "Leave out table headers and totals, e.g. for scripts")
+	rootCmd.PersistentFlags().Bool("wrap", false, "Wrap long table cells instead of truncating them to the terminal width")
 	viper.BindPFlag("api-key", rootCmd.PersistentFlags().Lookup("api-key"))
+	viper.BindPFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	viper.BindPFlag("base-url", rootCmd.PersistentFlags().Lookup("base-url"))
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -53,6 +73,62 @@ func initConfig() {
 	// Bind the api-key to the HUBSPOT_API_KEY environment variable
 	viper.BindEnv("api-key", "HUBSPOT_API_KEY")
 
+	viper.BindEnv("profile", "HUBSPOT_PROFILE")
+	viper.BindEnv("base-url", "HUBSPOT_BASE_URL")
+
 	// If a config file is found, read it in.
 	viper.ReadInConfig()
+
+	applyProfile()
+}
+
+// newClient builds a HubSpot client from the resolved credential and the
+// retry settings
+func newClient() (*hubspot.Client, error) {
+	ctx := rootCmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	transport, err := cassetteTransport()
+	if err != nil {
+		return nil, err
+	}
+
+	var opts []hubspot.Option
+	switch {
+	case transport != nil && replayFile != "":
+		opts = append(opts, hubspot.WithReplay(transport))
+	case transport != nil:
+		opts = append(opts, hubspot.WithTransport(transport))
+	}
+
+	// Replayed runs need no credential; the cassette never holds one anyway
+	cred := &credential{apiKey: "replay"}
+	if replayFile == "" {
+		cred, err = resolveCredential(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if cred.tokens != nil {
+		opts = append(opts, hubspot.WithTokenSource(cred.tokens))
+	}
+
+	policy := hubspot.DefaultRetryPolicy()
+	if viper.IsSet("retry.max-retries") {
+		policy.MaxRetries = viper.GetInt("retry.max-retries")
+	}
+	if viper.IsSet("retry.base-delay") {
+		policy.BaseDelay = viper.GetDuration("retry.base-delay")
+	}
+	if viper.IsSet("retry.max-delay") {
+		policy.MaxDelay = viper.GetDuration("retry.max-delay")
+	}
+
+	opts = append(opts, hubspot.WithRetryPolicy(policy))
+	if baseURL := viper.GetString("base-url"); baseURL != "" {
+		opts = append(opts, hubspot.WithBaseURL(baseURL))
+	}
+	return hubspot.NewClient(cred.apiKey, opts...), nil
 }
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/output"
+	"github.com/spf13/cobra"
+)
+
+var schemasCmd = &cobra.Command{
+	Use:   "schemas",
+	Short: "Manage HubSpot custom object schemas",
+	Long:  `List, inspect, create and delete custom object schemas.`,
+}
+
+var listSchemasCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all custom object schemas",
+	Long:  `List all custom object schemas defined in the portal.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		schemas, err := client.ListSchemas(cmd.Context())
+		if err != nil {
+			return fmt.Errorf("failed to list schemas: %w", err)
+		}
+
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		return printSchemas(schemas, format)
+	},
+}
+
+var getSchemaCmd = &cobra.Command{
+	Use:   "get [object-type]",
+	Short: "Get a custom object schema",
+	Long:  `Get a custom object schema by object type ID, fully qualified name, name or label.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		_, schema, err := client.ResolveObjectType(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if schema == nil {
+			return fmt.Errorf("%s is a standard object type and has no custom schema", args[0])
+		}
+
+		format, err := outputFormat(cmd)
+		if err != nil {
+			return err
+		}
+		if format != output.Table {
+			return printSchemas([]hubspot.ObjectSchema{*schema}, format)
+		}
+
+		fmt.Printf("Object Type ID:   %s\n", schema.ObjectTypeID)
+		fmt.Printf("Name:             %s\n", schema.Name)
+		fmt.Printf("Labels:           %s / %s\n", schema.Labels.Singular, schema.Labels.Plural)
+		fmt.Printf("Primary Property: %s\n", schema.PrimaryDisplayProperty)
+		fmt.Printf("Required:         %s\n", strings.Join(schema.RequiredProperties, ", "))
+		fmt.Printf("Searchable:       %s\n", strings.Join(schema.SearchableProperties, ", "))
+		fmt.Printf("Associations:     %s\n", strings.Join(schema.AssociatedObjects, ", "))
+		fmt.Println()
+		return printProperties(schema.Properties, output.Table)
+	},
+}
+
+var createSchemaCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Create a custom object schema",
+	Long: `Create a custom object schema from a JSON definition.
+
+The definition follows HubSpot's schema format, for example:
+
+  {
+    "name": "subscriptions",
+    "labels": {"singular": "Subscription", "plural": "Subscriptions"},
+    "primaryDisplayProperty": "plan",
+    "requiredProperties": ["plan"],
+    "properties": [
+      {"name": "plan", "label": "Plan", "type": "string", "fieldType": "text"}
+    ],
+    "associatedObjects": ["CONTACT"]
+  }`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		file, _ := cmd.Flags().GetString("file")
+		if file == "" {
+			return fmt.Errorf("a schema definition is required. Use --file (or --file - for stdin)")
+		}
+
+		var data []byte
+		var err error
+		if file == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(file)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read schema definition: %w", err)
+		}
+
+		var definition hubspot.SchemaDefinition
+		if err := json.Unmarshal(data, &definition); err != nil {
+			return fmt.Errorf("failed to parse schema definition: %w", err)
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		schema, err := client.CreateSchema(cmd.Context(), definition)
+		if err != nil {
+			return fmt.Errorf("failed to create schema: %w", err)
+		}
+
+		fmt.Println("Schema created successfully:")
+		return printSchemas([]hubspot.ObjectSchema{*schema}, output.Table)
+	},
+}
+
+var deleteSchemaCmd = &cobra.Command{
+	Use:   "delete [object-type]",
+	Short: "Delete a custom object schema",
+	Long: `Delete a custom object schema. HubSpot only allows deleting a schema
+once all of its records have been deleted.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		_, schema, err := client.ResolveObjectType(cmd.Context(), args[0])
+		if err != nil {
+			return err
+		}
+		if schema == nil {
+			return fmt.Errorf("%s is a standard object type and cannot be deleted", args[0])
+		}
+
+		force, _ := cmd.Flags().GetBool("force")
+		if !force {
+			fmt.Printf("Are you sure you want to delete schema %s (%s)? [y/N]: ", schema.Name, schema.ObjectTypeID)
+			var response string
+			fmt.Scanln(&response)
+			if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+				fmt.Println("Deletion cancelled.")
+				return nil
+			}
+		}
+
+		if err := client.DeleteSchema(cmd.Context(), schema.ObjectTypeID); err != nil {
+			return fmt.Errorf("failed to delete schema: %w", err)
+		}
+
+		fmt.Printf("Schema %s deleted successfully.\n", schema.Name)
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(schemasCmd)
+
+	schemasCmd.AddCommand(listSchemasCmd)
+	listSchemasCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+
+	schemasCmd.AddCommand(getSchemaCmd)
+	getSchemaCmd.Flags().StringP("format", "f", output.Table, output.Usage)
+
+	schemasCmd.AddCommand(createSchemaCmd)
+	createSchemaCmd.Flags().String("file", "", "Path to a JSON schema definition (- for stdin)")
+
+	schemasCmd.AddCommand(deleteSchemaCmd)
+	deleteSchemaCmd.Flags().Bool("force", false, "Skip confirmation prompt")
+}
+
+func printSchemas(schemas []hubspot.ObjectSchema, format string) error {
+	headers := []string{"objectTypeId", "name", "singular", "plural", "primaryDisplayProperty"}
+	if ok, err := printEncoded(format, schemas, headers, func(schema hubspot.ObjectSchema) []string {
+		return []string{schema.ObjectTypeID, schema.Name, schema.Labels.Singular, schema.Labels.Plural, schema.PrimaryDisplayProperty}
+	}); ok {
+		return err
+	}
+
+	// Table format
+	table := newTable(os.Stdout, "Object Type ID", "Name", "Singular", "Plural", "Primary Property")
+	for _, schema := range schemas {
+		if err := table.Append(schema.ObjectTypeID, schema.Name, schema.Labels.Singular, schema.Labels.Plural, schema.PrimaryDisplayProperty); err != nil {
+			return err
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return printTotal(os.Stdout, len(schemas), "schema(s)")
+}
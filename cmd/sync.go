@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/mirror"
+	"github.com/obay/hscli/internal/query"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var syncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Mirror contacts to the local disk",
+	Long: `Keep a local copy of every contact and its properties, so list, get and
+query can read it with --offline without calling HubSpot.
+
+The first sync pulls every contact. Later syncs only fetch the contacts
+whose lastmodifieddate is at or after the newest one already mirrored, or
+a minute before the previous sync started if that is earlier, and remove
+the contacts that were deleted or merged in HubSpot. Changing the
+mirrored properties, or --full, pulls every contact again.
+
+The mirror is kept in ~/.hscli-mirror, one directory per profile, or in the
+directory set by mirror-dir in the config file.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		kind := contactsKind
+		m, err := mirror.Open(mirrorDir(), kind.Name)
+		if err != nil {
+			return err
+		}
+
+		client, err := newClient()
+		if err != nil {
+			return err
+		}
+
+		// Keep the mirrored properties unless others were asked for
+		properties := m.State.Properties
+		if !m.Synced() || cmd.Flags().Changed("properties") || cmd.Flags().Changed("all-properties") {
+			properties, _, err = requestedProperties(cmd, client, kind)
+			if err != nil {
+				return err
+			}
+		}
+
+		full, _ := cmd.Flags().GetBool("full")
+		opts := mirror.Options{Properties: properties, ModifiedProperty: kind.modifiedProperty, Full: full}
+		tty := term.IsTerminal(int(os.Stderr.Fd()))
+		if tty {
+			opts.Progress = func(fetched int) {
+				if fetched%100 == 0 {
+					fmt.Fprintf(os.Stderr, "\rFetched %d %s...", fetched, kind.noun(fetched))
+				}
+			}
+		}
+
+		result, err := m.Sync(cmd.Context(), client, opts)
+		if tty {
+			fmt.Fprint(os.Stderr, "\r\033[K")
+		}
+		if err != nil {
+			return interrupted(cmd, fmt.Errorf("failed to sync %s: %w", kind.plural, err), 0)
+		}
+
+		mode := "Incremental"
+		if result.Full {
+			mode = "Full"
+		}
+		fmt.Printf("%s sync: %d added, %d updated, %d archived.\n", mode, result.Added, result.Updated, result.Archived)
+		fmt.Printf("%d %s mirrored in %s\n", m.Len(), kind.noun(m.Len()), mirrorDir())
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(syncCmd)
+
+	syncCmd.Flags().Bool("full", false, "Pull every contact again instead of only the modified ones")
+	syncCmd.Flags().String("properties", "", "Comma-separated properties to mirror; @name expands a property set from the config file")
+	syncCmd.Flags().Bool("all-properties", false, "Mirror every property defined for contacts")
+	syncCmd.MarkFlagsMutuallyExclusive("properties", "all-properties")
+}
+
+// mirrorDir returns where hscli sync keeps the local mirror
+func mirrorDir() string {
+	if dir := viper.GetString("mirror-dir"); dir != "" {
+		return dir
+	}
+	name := ".hscli-mirror"
+	if activeProfile != "" {
+		// Each profile mirrors its own portal
+		name = ".hscli-mirror-" + activeProfile
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return name
+	}
+	return filepath.Join(home, name)
+}
+
+// addOfflineFlag adds --offline to a read command of a type hscli sync mirrors
+func addOfflineFlag(cmd *cobra.Command, kind objectKind) {
+	if kind.modifiedProperty == "" {
+		return
+	}
+	cmd.Flags().Bool("offline", false, "Read from the local mirror kept by hscli sync instead of HubSpot")
+}
+
+// isOffline reports whether a read command should use the local mirror
+func isOffline(cmd *cobra.Command) bool {
+	offline, _ := cmd.Flags().GetBool("offline")
+	return offline
+}
+
+// openOffline opens the mirror for an --offline read and returns the
+// properties to read and the kind to render them with. --all-properties
+// selects every mirrored property.
+func openOffline(cmd *cobra.Command, kind objectKind) (*mirror.Mirror, []string, objectKind, error) {
+	m, err := openMirror(kind)
+	if err != nil {
+		return nil, nil, kind, err
+	}
+
+	if all, _ := cmd.Flags().GetBool("all-properties"); all {
+		return m, m.State.Properties, kind, nil
+	}
+	properties, view, err := selectProperties(cmd, nil, kind)
+	if err != nil {
+		return nil, nil, kind, err
+	}
+	if missing := m.Missing(properties); len(missing) > 0 {
+		return nil, nil, kind, fmt.Errorf("the local mirror does not have %s; add them with hscli sync --properties",
+			strings.Join(missing, ", "))
+	}
+	return m, properties, view, nil
+}
+
+// openMirror opens the mirror of a kind, which hscli sync must have pulled
+func openMirror(kind objectKind) (*mirror.Mirror, error) {
+	m, err := mirror.Open(mirrorDir(), kind.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !m.Synced() {
+		return nil, fmt.Errorf("there is no local mirror of %s, run hscli sync first", kind.plural)
+	}
+	return m, nil
+}
+
+// listOffline prints the first limit mirrored objects, or all of them
+func listOffline(cmd *cobra.Command, kind objectKind, limit int, all bool) error {
+	m, properties, view, err := openOffline(cmd, kind)
+	if err != nil {
+		return err
+	}
+	objects := m.Objects()
+	if !all && len(objects) > limit {
+		objects = objects[:limit]
+	}
+
+	w, err := objectWriterFor(cmd, view)
+	if err != nil {
+		return err
+	}
+	for _, object := range objects {
+		if err := w.Write(mirror.Select(object, properties)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// getOffline prints a mirrored object
+func getOffline(cmd *cobra.Command, kind objectKind, id string) error {
+	m, properties, view, err := openOffline(cmd, kind)
+	if err != nil {
+		return err
+	}
+	object, ok := m.Get(id)
+	if !ok {
+		return fmt.Errorf("%s %s is not in the local mirror", kind.singular, id)
+	}
+
+	w, err := objectWriterFor(cmd, view)
+	if err != nil {
+		return err
+	}
+	if err := w.Write(mirror.Select(object, properties)); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+// queryOffline prints the mirrored objects matching a query, or with
+// --group-by and --agg the aggregates of the matching objects
+func queryOffline(cmd *cobra.Command, kind objectKind, input, text string, sorts []hubspot.Sort, limit int, all bool) error {
+	groupBy, _ := cmd.Flags().GetStringSlice("group-by")
+	aggSpecs, _ := cmd.Flags().GetStringSlice("agg")
+	aggregates, err := query.ParseAggregates(aggSpecs)
+	if err != nil {
+		return err
+	}
+	aggregating := len(groupBy) > 0 || len(aggregates) > 0
+
+	// Aggregates don't print the objects, so only need the mirror
+	var (
+		m          *mirror.Mirror
+		properties []string
+		view       objectKind
+	)
+	if aggregating {
+		m, err = openMirror(kind)
+	} else {
+		m, properties, view, err = openOffline(cmd, kind)
+	}
+	if err != nil {
+		return err
+	}
+
+	match := func(query.Getter) bool { return true }
+	var used []string
+	if input != "" {
+		if query.IsExpression(input) {
+			expr, err := query.Parse(input)
+			if err != nil {
+				return fmt.Errorf("invalid query: %w", err)
+			}
+			match, used = expr.Match, expr.Properties()
+		} else {
+			groups := hubspot.SimpleQuery(input, kind.SearchProperty)
+			match = func(get query.Getter) bool { return query.MatchGroups(groups, get) }
+			used = []string{groups[0].Filters[0].PropertyName}
+		}
+	}
+	for _, s := range sorts {
+		used = append(used, s.PropertyName)
+	}
+
+	used = append(used, groupBy...)
+	for _, a := range aggregates {
+		if a.Property != "" {
+			used = append(used, a.Property)
+		}
+	}
+	if missing := m.Missing(used); len(missing) > 0 {
+		return fmt.Errorf("the local mirror does not have %s; add them with hscli sync --properties",
+			strings.Join(missing, ", "))
+	}
+
+	// Free text matches the searchable properties that are mirrored
+	var searchable []string
+	for _, name := range query.SearchableProperties[kind.Name] {
+		if len(m.Missing([]string{name})) == 0 {
+			searchable = append(searchable, name)
+		}
+	}
+
+	var matched []hubspot.Object
+	for _, object := range m.Objects() {
+		get := query.ObjectGetter(object)
+		if !match(get) {
+			continue
+		}
+		if text != "" {
+			values := make([]string, len(searchable))
+			for i, name := range searchable {
+				values[i] = get(name)
+			}
+			if !query.MatchText(values, text) {
+				continue
+			}
+		}
+		matched = append(matched, object)
+	}
+	if len(sorts) > 0 {
+		sort.SliceStable(matched, func(i, j int) bool {
+			return query.Less(sorts, query.ObjectGetter(matched[i]), query.ObjectGetter(matched[j]))
+		})
+	}
+
+	if aggregating {
+		if len(aggregates) == 0 {
+			aggregates = []query.Aggregate{{Func: "count"}}
+		}
+		rows := make([]query.Getter, len(matched))
+		for i, object := range matched {
+			rows[i] = query.ObjectGetter(object)
+		}
+		return printGroups(cmd, groupBy, aggregates, query.Summarize(rows, groupBy, aggregates))
+	}
+
+	if !all && len(matched) > limit {
+		matched = matched[:limit]
+	}
+	w, err := objectWriterFor(cmd, view)
+	if err != nil {
+		return err
+	}
+	for _, object := range matched {
+		if err := w.Write(mirror.Select(object, properties)); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// printGroups prints aggregated groups, one row per group
+func printGroups(cmd *cobra.Command, groupBy []string, aggregates []query.Aggregate, groups []query.Group) error {
+	if spec, _ := cmd.Flags().GetString("output"); spec != "" {
+		return fmt.Errorf("--output cannot render aggregates, use --format instead")
+	}
+	format, err := outputFormat(cmd)
+	if err != nil {
+		return err
+	}
+
+	headers := append([]string(nil), groupBy...)
+	for _, a := range aggregates {
+		headers = append(headers, a.Name())
+	}
+	rows := make([]groupRow, len(groups))
+	for i, g := range groups {
+		rows[i] = groupRow{headers: headers, values: make([]interface{}, 0, len(headers))}
+		for _, key := range g.Keys {
+			rows[i].values = append(rows[i].values, key)
+		}
+		rows[i].values = append(rows[i].values, g.Values...)
+	}
+	if ok, err := printEncoded(format, rows, headers, groupRow.cells); ok {
+		return err
+	}
+
+	table := newTable(os.Stdout, headers...)
+	for _, row := range rows {
+		if err := table.Append(row.cells()...); err != nil {
+			return err
+		}
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	return printTotal(os.Stdout, len(groups), "group(s)")
+}
+
+// groupRow is a row of aggregated results. It encodes as an object keyed
+// by the column names, in column order.
+type groupRow struct {
+	headers []string
+	values  []interface{}
+}
+
+func (r groupRow) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, header := range r.headers {
+		key, err := json.Marshal(header)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(r.values[i])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (r groupRow) cells() []string {
+	cells := make([]string, len(r.values))
+	for i, value := range r.values {
+		cells[i] = formatAggregate(value)
+	}
+	return cells
+}
+
+// formatAggregate renders an aggregate value as a table or CSV cell
+func formatAggregate(value interface{}) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	default:
+		return fmt.Sprint(value)
+	}
+}
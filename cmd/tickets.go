@@ -0,0 +1,30 @@
+package cmd
+
+import "github.com/obay/hscli/internal/hubspot"
+
+// ticketsKind describes the tickets command tree
+var ticketsKind = objectKind{
+	ObjectType: hubspot.Tickets,
+	command:    "tickets",
+	singular:   "ticket",
+	plural:     "tickets",
+	fields: []fieldFlag{
+		{name: "subject", shorthand: "s", property: "subject", usage: "Ticket subject"},
+		{name: "content", property: "content", usage: "Ticket description"},
+		{name: "pipeline", property: "hs_pipeline", usage: "Pipeline ID (e.g., 0)"},
+		{name: "stage", property: "hs_pipeline_stage", usage: "Pipeline stage ID"},
+		{name: "priority", property: "hs_ticket_priority", usage: "Priority (LOW, MEDIUM, HIGH)"},
+	},
+	associationTargets: []string{"contacts", "companies", "deals"},
+	columns: []column{
+		{header: "Subject", property: "subject"},
+		{header: "Pipeline", property: "hs_pipeline"},
+		{header: "Stage", property: "hs_pipeline_stage"},
+		{header: "Priority", property: "hs_ticket_priority"},
+		{header: "Created", property: "createdate"},
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(newObjectCmd(ticketsKind))
+}
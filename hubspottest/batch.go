@@ -0,0 +1,146 @@
+package hubspottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// batchRequest is the body of the batch endpoints
+type batchRequest struct {
+	Inputs []struct {
+		ID         string                 `json:"id"`
+		IDProperty string                 `json:"idProperty"`
+		Properties map[string]interface{} `json:"properties"`
+	} `json:"inputs"`
+	IDProperty string   `json:"idProperty"`
+	Properties []string `json:"properties"`
+}
+
+// batchResponse is the body of a batch response; HubSpot answers 207 when
+// some inputs failed and the others succeeded
+type batchResponse struct {
+	Status  string               `json:"status"`
+	Results []hubspot.Object     `json:"results"`
+	Errors  []hubspot.BatchError `json:"errors,omitempty"`
+}
+
+// batch serves /crm/v3/objects/{type}/batch/{action}. Like HubSpot, invalid
+// property values reject the whole batch, while unknown IDs in reads and
+// updates are reported per input.
+func (s *Server) batch(w http.ResponseWriter, r *http.Request, objectType, action string) {
+	switch action {
+	case "read", "create", "update", "upsert", "archive":
+	default:
+		writeError(w, http.StatusNotFound, hubspot.APIError{
+			Status:   "error",
+			Message:  fmt.Sprintf("Unable to find a resource for %s %s", r.Method, r.URL.Path),
+			Category: hubspot.CategoryNotFound,
+		})
+		return
+	}
+
+	var body batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeValidation(w, fmt.Sprintf("Invalid input JSON on line 1: %v", err), nil)
+		return
+	}
+	if len(body.Inputs) > hubspot.MaxBatchSize {
+		writeValidation(w, fmt.Sprintf("Batch input size %d exceeds the limit of %d", len(body.Inputs), hubspot.MaxBatchSize), nil)
+		return
+	}
+
+	seen := make(map[string]bool)
+	for _, input := range body.Inputs {
+		id := strings.ToLower(input.ID)
+		if id != "" && seen[id] {
+			writeValidation(w, fmt.Sprintf("Duplicate IDs found in batch input: [%s]. IDs must be unique", input.ID), nil)
+			return
+		}
+		seen[id] = true
+	}
+
+	// Validate every input first, so a rejected batch changes nothing
+	values := make([]map[string]string, len(body.Inputs))
+	if action == "create" || action == "update" || action == "upsert" {
+		var details []hubspot.ErrorDetail
+		for i, input := range body.Inputs {
+			properties, invalid := s.validateProperties(objectType, input.Properties)
+			values[i] = properties
+			details = append(details, invalid...)
+		}
+		if len(details) > 0 {
+			writeValidation(w, validationMessage(details), details)
+			return
+		}
+	}
+
+	resp := batchResponse{Status: "COMPLETE", Results: []hubspot.Object{}}
+	var missing []string
+	now := s.now().UTC()
+
+	for i, input := range body.Inputs {
+		switch action {
+		case "read":
+			rec := s.lookup(objectType, input.ID, body.IDProperty)
+			if rec == nil {
+				missing = append(missing, input.ID)
+				continue
+			}
+			resp.Results = append(resp.Results, s.render(objectType, rec, body.Properties))
+
+		case "create":
+			rec := s.insert(objectType, values[i])
+			resp.Results = append(resp.Results, s.render(objectType, rec, keys(values[i])))
+
+		case "update", "upsert":
+			idProperty := input.IDProperty
+			rec := s.lookup(objectType, input.ID, idProperty)
+			if rec == nil && action == "update" {
+				missing = append(missing, input.ID)
+				continue
+			}
+			if rec == nil {
+				if idProperty != "" && idProperty != "hs_object_id" {
+					values[i][idProperty] = input.ID
+				}
+				rec = s.insert(objectType, values[i])
+				object := s.render(objectType, rec, keys(values[i]))
+				object.New = true
+				resp.Results = append(resp.Results, object)
+				continue
+			}
+			for name, value := range values[i] {
+				s.setProperty(rec, name, value, now)
+			}
+			rec.updatedAt = now
+			rec.properties["lastmodifieddate"] = formatTime(now)
+			resp.Results = append(resp.Results, s.render(objectType, rec, keys(values[i])))
+
+		case "archive":
+			if rec := s.lookup(objectType, input.ID, ""); rec != nil {
+				s.remove(objectType, rec)
+			}
+		}
+	}
+
+	switch {
+	case action == "archive":
+		w.WriteHeader(http.StatusNoContent)
+	case len(missing) > 0:
+		resp.Errors = []hubspot.BatchError{{
+			Status:   "error",
+			Category: hubspot.CategoryNotFound,
+			Message:  fmt.Sprintf("Could not get some %s objects, they may be deleted or not exist. Check that ids are valid.", strings.ToUpper(objectType)),
+			Context:  map[string][]string{"ids": missing},
+		}}
+		writeJSON(w, http.StatusMultiStatus, resp)
+	case action == "create":
+		writeJSON(w, http.StatusCreated, resp)
+	default:
+		writeJSON(w, http.StatusOK, resp)
+	}
+}
@@ -0,0 +1,83 @@
+package hubspottest
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json;charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError sends a HubSpot error envelope with a fresh correlation ID
+func writeError(w http.ResponseWriter, status int, apiErr hubspot.APIError) {
+	apiErr.CorrelationID = correlationID()
+	writeJSON(w, status, apiErr)
+}
+
+func writeValidation(w http.ResponseWriter, message string, details []hubspot.ErrorDetail) {
+	writeError(w, http.StatusBadRequest, hubspot.APIError{
+		Status:   "error",
+		Message:  message,
+		Category: hubspot.CategoryValidation,
+		Errors:   details,
+	})
+}
+
+func writeNotFound(w http.ResponseWriter, id string) {
+	writeError(w, http.StatusNotFound, hubspot.APIError{
+		Status:   "error",
+		Message:  fmt.Sprintf("Object not found.  objectId are usually numeric. (id: %s)", id),
+		Category: hubspot.CategoryNotFound,
+	})
+}
+
+// writeFailure sends the payload HubSpot uses for an injected or rate limit failure status
+func writeFailure(w http.ResponseWriter, status int) {
+	switch status {
+	case http.StatusTooManyRequests:
+		// Rate limit errors have their own envelope
+		w.Header().Set("Content-Type", "application/json;charset=utf-8")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":        "error",
+			"message":       "You have reached your secondly limit.",
+			"errorType":     "RATE_LIMIT",
+			"correlationId": correlationID(),
+			"policyName":    "SECONDLY",
+			"category":      hubspot.CategoryRateLimit,
+		})
+	case http.StatusUnauthorized:
+		writeError(w, status, hubspot.APIError{
+			Status:   "error",
+			Message:  "The OAuth token used to make this call expired.",
+			Category: "EXPIRED_AUTHENTICATION",
+		})
+	case http.StatusForbidden:
+		writeError(w, status, hubspot.APIError{
+			Status:   "error",
+			Message:  "This app hasn't been granted all required scopes to make this call.",
+			Category: hubspot.CategoryMissingScope,
+		})
+	default:
+		writeError(w, status, hubspot.APIError{
+			Status:   "error",
+			Message:  http.StatusText(status),
+			Category: "INTERNAL_ERROR",
+		})
+	}
+}
+
+func correlationID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	h := hex.EncodeToString(b)
+	return h[:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:]
+}
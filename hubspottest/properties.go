@@ -0,0 +1,108 @@
+package hubspottest
+
+import (
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// readOnly lists properties that HubSpot maintains itself
+var readOnly = map[string]bool{
+	"hs_object_id":     true,
+	"createdate":       true,
+	"lastmodifieddate": true,
+}
+
+// lifecycleStages are the options of the lifecyclestage property
+var lifecycleStages = []string{"subscriber", "lead", "marketingqualifiedlead", "salesqualifiedlead", "opportunity", "customer", "evangelist", "other"}
+
+// leadStatuses are the options of the hs_lead_status property
+var leadStatuses = []string{"NEW", "OPEN", "IN_PROGRESS", "OPEN_DEAL", "UNQUALIFIED", "ATTEMPTED_TO_CONTACT", "CONNECTED", "BAD_TIMING"}
+
+// standardProperties returns the property definitions of the standard
+// object types: the hubspot package's default properties plus a few
+// commonly used ones
+func standardProperties() map[string][]hubspot.Property {
+	extra := map[string][]string{
+		hubspot.Contacts.Name:  {"phone", "mobilephone", "jobtitle", "website", "city", "country", "notes"},
+		hubspot.Companies.Name: {"phone", "website", "numberofemployees", "annualrevenue", "description"},
+		hubspot.Deals.Name:     {"description", "hubspot_owner_id"},
+		hubspot.Tickets.Name:   {"content", "hubspot_owner_id"},
+	}
+	numeric := map[string]bool{
+		"amount": true, "price": true, "quantity": true, "numberofemployees": true, "annualrevenue": true,
+	}
+
+	definitions := make(map[string][]hubspot.Property)
+	for _, objectType := range hubspot.StandardObjectTypes {
+		seen := make(map[string]bool)
+		var props []hubspot.Property
+		add := func(name string) {
+			if seen[name] {
+				return
+			}
+			seen[name] = true
+
+			prop := hubspot.Property{
+				Name:      name,
+				Label:     label(name),
+				Type:      "string",
+				FieldType: "text",
+				ModificationMetadata: &hubspot.PropertyModificationMetadata{
+					ReadOnlyValue: readOnly[name],
+					Archivable:    !readOnly[name],
+				},
+			}
+			switch {
+			case readOnly[name] && name != "hs_object_id":
+				prop.Type, prop.FieldType = "datetime", "date"
+			case numeric[name]:
+				prop.Type, prop.FieldType = "number", "number"
+			case name == "lifecyclestage":
+				prop.Type, prop.FieldType, prop.Options = "enumeration", "radio", options(lifecycleStages)
+			case name == "hs_lead_status":
+				prop.Type, prop.FieldType, prop.Options = "enumeration", "radio", options(leadStatuses)
+			}
+			props = append(props, prop)
+		}
+
+		for _, name := range objectType.DefaultProperties {
+			add(name)
+		}
+		for _, name := range extra[objectType.Name] {
+			add(name)
+		}
+		for _, name := range []string{"hs_object_id", "createdate", "lastmodifieddate"} {
+			add(name)
+		}
+		definitions[objectType.Name] = props
+	}
+	return definitions
+}
+
+// defaultProperties returns the properties HubSpot returns when none are requested
+func defaultProperties(objectType string) []string {
+	for _, t := range hubspot.StandardObjectTypes {
+		if t.Name == objectType {
+			return t.DefaultProperties
+		}
+	}
+	return nil
+}
+
+func options(values []string) []hubspot.PropertyOption {
+	opts := make([]hubspot.PropertyOption, len(values))
+	for i, v := range values {
+		opts[i] = hubspot.PropertyOption{Label: label(v), Value: v}
+	}
+	return opts
+}
+
+// label turns a property name into a readable label, e.g. hs_lead_status → Hs Lead Status
+func label(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return r == '_' })
+	for i, w := range words {
+		words[i] = strings.ToUpper(w[:1]) + strings.ToLower(w[1:])
+	}
+	return strings.Join(words, " ")
+}
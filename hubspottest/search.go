@@ -0,0 +1,117 @@
+package hubspottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/query"
+)
+
+// Search limits enforced by HubSpot
+const (
+	maxSearchResults   = 10000
+	defaultSearchLimit = 10
+)
+
+func (s *Server) search(w http.ResponseWriter, r *http.Request, objectType string) {
+	var req hubspot.SearchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeValidation(w, fmt.Sprintf("Invalid input JSON on line 1: %v", err), nil)
+		return
+	}
+	if msg := validateSearch(req); msg != "" {
+		writeValidation(w, msg, nil)
+		return
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+	if offset, err := strconv.Atoi(req.After); err == nil && offset+limit > maxSearchResults {
+		writeValidation(w, fmt.Sprintf("Paging past %d results is not supported", maxSearchResults), nil)
+		return
+	}
+
+	matches := s.sorted(objectType, func(rec *record) bool {
+		return query.MatchGroups(req.FilterGroups, rec.get) && matchText(rec, objectType, req.Query)
+	})
+	sortRecords(matches, req.Sorts)
+	page, next, err := paginate(matches, req.After, limit)
+	if err != nil {
+		writeValidation(w, err.Error(), nil)
+		return
+	}
+
+	resp := hubspot.ObjectResponse{Results: make([]hubspot.Object, 0, len(page)), Total: len(matches)}
+	for _, rec := range page {
+		resp.Results = append(resp.Results, s.render(objectType, rec, req.Properties))
+	}
+	if next != "" {
+		resp.Paging = &hubspot.Paging{Next: &hubspot.NextPage{After: next}}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// get returns a Getter for the properties of a record
+func (rec *record) get(property string) string {
+	return rec.properties[property]
+}
+
+// matchText matches a free-text query against the record's default
+// searchable properties
+func matchText(rec *record, objectType, text string) bool {
+	var values []string
+	for _, name := range query.SearchableProperties[objectType] {
+		values = append(values, rec.properties[name])
+	}
+	return query.MatchText(values, text)
+}
+
+// sortRecords orders records by the sorts, keeping ID order for ties.
+// Records without a value sort last.
+func sortRecords(records []*record, sorts []hubspot.Sort) {
+	if len(sorts) == 0 {
+		return
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		return query.Less(sorts, records[i].get, records[j].get)
+	})
+}
+
+// validateSearch applies HubSpot's limits on filter groups and filters
+func validateSearch(req hubspot.SearchRequest) string {
+	if len(req.FilterGroups) > hubspot.MaxFilterGroups {
+		return fmt.Sprintf("Too many filter groups: %d. The maximum is %d.", len(req.FilterGroups), hubspot.MaxFilterGroups)
+	}
+
+	total := 0
+	for _, group := range req.FilterGroups {
+		if len(group.Filters) > hubspot.MaxFiltersPerGroup {
+			return fmt.Sprintf("Too many filters in a filter group: %d. The maximum is %d.", len(group.Filters), hubspot.MaxFiltersPerGroup)
+		}
+		total += len(group.Filters)
+		for _, f := range group.Filters {
+			if !knownOperators[f.Operator] {
+				return fmt.Sprintf("Invalid operator %q for property %s", f.Operator, f.PropertyName)
+			}
+		}
+	}
+	if total > hubspot.MaxFilters {
+		return fmt.Sprintf("Too many filters: %d. The maximum is %d.", total, hubspot.MaxFilters)
+	}
+	return ""
+}
+
+var knownOperators = map[string]bool{
+	"EQ": true, "NEQ": true, "LT": true, "LTE": true, "GT": true, "GTE": true,
+	"BETWEEN": true, "IN": true, "NOT_IN": true, "HAS_PROPERTY": true, "NOT_HAS_PROPERTY": true,
+	"CONTAINS_TOKEN": true, "NOT_CONTAINS_TOKEN": true,
+}
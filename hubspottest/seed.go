@@ -0,0 +1,51 @@
+package hubspottest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+var (
+	firstNames = []string{"Ada", "Grace", "Alan", "Katherine", "Linus", "Margaret", "Dennis", "Barbara", "Ken", "Frances"}
+	lastNames  = []string{"Lovelace", "Hopper", "Turing", "Johnson", "Torvalds", "Hamilton", "Ritchie", "Liskov", "Thompson", "Allen"}
+	companies  = []string{"Analytical Engines", "Cobol Systems", "Enigma Labs", "Orbital Dynamics", "Kernel Works"}
+)
+
+// Seed adds n sample contacts and one company for every five contacts. The
+// data is deterministic so scripts can rely on it.
+func (s *Server) Seed(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < (n+4)/5; i++ {
+		name := companies[i%len(companies)]
+		if i >= len(companies) {
+			name = fmt.Sprintf("%s %d", name, i/len(companies)+1)
+		}
+		s.insert(hubspot.Companies.Name, map[string]string{
+			"name":     name,
+			"domain":   domain(name),
+			"industry": "COMPUTER_SOFTWARE",
+		})
+	}
+
+	for i := 0; i < n; i++ {
+		first := firstNames[i%len(firstNames)]
+		last := lastNames[(i/len(firstNames)+i)%len(lastNames)]
+		company := companies[i/5%len(companies)]
+		s.insert(hubspot.Contacts.Name, map[string]string{
+			"email":          fmt.Sprintf("%s.%s%d@%s", strings.ToLower(first), strings.ToLower(last), i+1, domain(company)),
+			"firstname":      first,
+			"lastname":       last,
+			"company":        company,
+			"lifecyclestage": lifecycleStages[i%len(lifecycleStages)],
+			"hs_lead_status": leadStatuses[i%len(leadStatuses)],
+		})
+	}
+}
+
+func domain(company string) string {
+	return strings.ToLower(strings.ReplaceAll(company, " ", "")) + ".example.com"
+}
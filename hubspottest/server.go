@@ -0,0 +1,642 @@
+// Package hubspottest provides an in-memory fake of the HubSpot CRM API for
+// tests and offline use. It implements the object CRUD, batch, search,
+// property and pagination endpoints used by hubspot.Client and answers with HubSpot's
+// error envelopes, including 429 responses when a rate limit is set. Point
+// any HubSpot client, including those of other modules, at the server's URL:
+//
+//	server := httptest.NewServer(hubspottest.New())
+//	defer server.Close()
+//	client := hubspot.NewClient("test", hubspot.WithBaseURL(server.URL))
+package hubspottest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// Paging limits enforced by HubSpot
+const (
+	maxListLimit   = 100
+	maxSearchLimit = 200
+)
+
+// record is a stored object with the value history of its properties
+type record struct {
+	id         int64
+	properties map[string]string
+	history    map[string][]hubspot.PropertyVersion
+	createdAt  time.Time
+	updatedAt  time.Time
+	archivedAt time.Time
+}
+
+// Server is an in-memory HubSpot CRM API. It is safe for concurrent use.
+type Server struct {
+	// APIKey, when set, is the only bearer token accepted; any token is accepted otherwise
+	APIKey string
+	// RateLimit is the number of requests allowed per second; 0 disables rate limiting
+	RateLimit int
+
+	mu          sync.Mutex
+	objects     map[string]map[int64]*record
+	archived    map[string]map[int64]*record
+	properties  map[string][]hubspot.Property
+	nextID      int64
+	failures    []int
+	window      time.Time
+	windowCount int
+	requests    int
+	now         func() time.Time
+}
+
+// New creates a server with the standard object types and their common
+// properties, and no records
+func New() *Server {
+	s := &Server{
+		objects:    make(map[string]map[int64]*record),
+		archived:   make(map[string]map[int64]*record),
+		properties: make(map[string][]hubspot.Property),
+		nextID:     1,
+		now:        time.Now,
+	}
+	for name, props := range standardProperties() {
+		s.objects[name] = make(map[int64]*record)
+		s.properties[name] = props
+	}
+	return s
+}
+
+// AddObject stores an object directly, bypassing validation, and returns it
+func (s *Server) AddObject(objectType string, properties map[string]string) hubspot.Object {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rec := s.insert(objectType, properties)
+	return s.render(objectType, rec, nil)
+}
+
+// FailNext makes the next n requests fail with the given status code and a
+// matching HubSpot error payload, e.g. to exercise retries
+func (s *Server) FailNext(n, status int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := 0; i < n; i++ {
+		s.failures = append(s.failures, status)
+	}
+}
+
+// Requests returns the number of requests the server has received
+func (s *Server) Requests() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.requests
+}
+
+// Count returns the number of stored objects of a type
+func (s *Server) Count(objectType string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.objects[objectType])
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.requests++
+
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, hubspot.APIError{
+			Status:   "error",
+			Message:  "Authentication credentials not found. This API supports OAuth 2.0 authentication and you can find more details at https://developers.hubspot.com/docs/methods/auth/oauth-overview",
+			Category: hubspot.CategoryUnauthorized,
+		})
+		return
+	}
+	if !s.allow(w) {
+		return
+	}
+	if len(s.failures) > 0 {
+		status := s.failures[0]
+		s.failures = s.failures[1:]
+		writeFailure(w, status)
+		return
+	}
+
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(segments) >= 4 && segments[0] == "crm" && segments[1] == "v3" && segments[2] == "objects":
+		s.serveObjects(w, r, segments[3], segments[4:])
+	case len(segments) == 4 && segments[0] == "crm" && segments[1] == "v3" && segments[2] == "properties":
+		s.serveProperties(w, r, segments[3])
+	default:
+		writeError(w, http.StatusNotFound, hubspot.APIError{
+			Status:   "error",
+			Message:  fmt.Sprintf("Unable to find a resource for %s %s", r.Method, r.URL.Path),
+			Category: hubspot.CategoryNotFound,
+		})
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	return s.APIKey == "" || token == s.APIKey
+}
+
+// allow applies the per-second rate limit, sending HubSpot's rate limit
+// headers and a 429 response once the limit is exceeded
+func (s *Server) allow(w http.ResponseWriter) bool {
+	if s.RateLimit <= 0 {
+		return true
+	}
+
+	now := s.now()
+	if now.Sub(s.window) >= time.Second {
+		s.window = now
+		s.windowCount = 0
+	}
+	s.windowCount++
+
+	remaining := s.RateLimit - s.windowCount
+	if remaining < 0 {
+		remaining = 0
+	}
+	w.Header().Set("X-HubSpot-RateLimit-Secondly", strconv.Itoa(s.RateLimit))
+	w.Header().Set("X-HubSpot-RateLimit-Secondly-Remaining", strconv.Itoa(remaining))
+
+	if s.windowCount > s.RateLimit {
+		w.Header().Set("Retry-After", "1")
+		writeFailure(w, http.StatusTooManyRequests)
+		return false
+	}
+	return true
+}
+
+func (s *Server) serveObjects(w http.ResponseWriter, r *http.Request, objectType string, rest []string) {
+	if _, ok := s.objects[objectType]; !ok {
+		writeError(w, http.StatusBadRequest, hubspot.APIError{
+			Status:   "error",
+			Message:  fmt.Sprintf("Unable to infer object type from: %s", objectType),
+			Category: hubspot.CategoryValidation,
+		})
+		return
+	}
+
+	switch {
+	case len(rest) == 0 && r.Method == "GET":
+		s.list(w, r, objectType)
+	case len(rest) == 0 && r.Method == "POST":
+		s.create(w, r, objectType)
+	case len(rest) == 1 && rest[0] == "search" && r.Method == "POST":
+		s.search(w, r, objectType)
+	case len(rest) == 2 && rest[0] == "batch" && r.Method == "POST":
+		s.batch(w, r, objectType, rest[1])
+	case len(rest) == 1 && r.Method == "GET":
+		s.get(w, r, objectType, rest[0])
+	case len(rest) == 1 && r.Method == "PATCH":
+		s.update(w, r, objectType, rest[0])
+	case len(rest) == 1 && r.Method == "DELETE":
+		s.archive(w, r, objectType, rest[0])
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request, objectType string) {
+	limit, err := parseLimit(r.URL.Query().Get("limit"), 10, maxListLimit)
+	if err != nil {
+		writeValidation(w, err.Error(), nil)
+		return
+	}
+	properties := splitList(r.URL.Query().Get("properties"))
+
+	records := s.sorted(objectType, nil)
+	if r.URL.Query().Get("archived") == "true" {
+		records = sortedByID(s.archived[objectType], nil)
+	}
+	page, next, err := paginate(records, r.URL.Query().Get("after"), limit)
+	if err != nil {
+		writeValidation(w, err.Error(), nil)
+		return
+	}
+
+	resp := hubspot.ObjectResponse{Results: make([]hubspot.Object, 0, len(page))}
+	for _, rec := range page {
+		resp.Results = append(resp.Results, s.render(objectType, rec, properties))
+	}
+	if next != "" {
+		resp.Paging = &hubspot.Paging{Next: &hubspot.NextPage{After: next}}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request, objectType, id string) {
+	rec := s.lookup(objectType, id, r.URL.Query().Get("idProperty"))
+	if rec == nil {
+		writeNotFound(w, id)
+		return
+	}
+
+	object := s.render(objectType, rec, splitList(r.URL.Query().Get("properties")))
+	if withHistory := splitList(r.URL.Query().Get("propertiesWithHistory")); len(withHistory) > 0 {
+		object.PropertiesWithHistory = make(map[string][]hubspot.PropertyVersion)
+		for _, name := range withHistory {
+			versions := rec.history[name]
+			// Newest first, like HubSpot
+			reversed := make([]hubspot.PropertyVersion, len(versions))
+			for i, v := range versions {
+				reversed[len(versions)-1-i] = v
+			}
+			object.PropertiesWithHistory[name] = reversed
+		}
+	}
+	writeJSON(w, http.StatusOK, object)
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request, objectType string) {
+	properties, ok := s.decodeProperties(w, r, objectType)
+	if !ok {
+		return
+	}
+	if objectType == hubspot.Contacts.Name && properties["email"] != "" {
+		if existing := s.findBy(objectType, "email", properties["email"]); existing != nil {
+			writeError(w, http.StatusConflict, hubspot.APIError{
+				Status:   "error",
+				Message:  fmt.Sprintf("Contact already exists. Existing ID: %d", existing.id),
+				Category: hubspot.CategoryConflict,
+			})
+			return
+		}
+	}
+
+	rec := s.insert(objectType, properties)
+	writeJSON(w, http.StatusCreated, s.render(objectType, rec, keys(properties)))
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request, objectType, id string) {
+	rec := s.lookup(objectType, id, r.URL.Query().Get("idProperty"))
+	if rec == nil {
+		writeNotFound(w, id)
+		return
+	}
+
+	properties, ok := s.decodeProperties(w, r, objectType)
+	if !ok {
+		return
+	}
+
+	now := s.now().UTC()
+	for name, value := range properties {
+		s.setProperty(rec, name, value, now)
+	}
+	rec.updatedAt = now
+	rec.properties["lastmodifieddate"] = formatTime(now)
+
+	writeJSON(w, http.StatusOK, s.render(objectType, rec, keys(properties)))
+}
+
+func (s *Server) archive(w http.ResponseWriter, r *http.Request, objectType, id string) {
+	// HubSpot answers 204 whether or not the object exists
+	if rec := s.lookup(objectType, id, ""); rec != nil {
+		s.remove(objectType, rec)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) serveProperties(w http.ResponseWriter, r *http.Request, objectType string) {
+	props, ok := s.properties[objectType]
+	if !ok || r.Method != "GET" {
+		writeError(w, http.StatusBadRequest, hubspot.APIError{
+			Status:   "error",
+			Message:  fmt.Sprintf("Unable to infer object type from: %s", objectType),
+			Category: hubspot.CategoryValidation,
+		})
+		return
+	}
+	writeJSON(w, http.StatusOK, hubspot.PropertiesResponse{Results: props})
+}
+
+// decodeProperties reads a {"properties": {...}} body and validates it
+// against the object type's property definitions
+func (s *Server) decodeProperties(w http.ResponseWriter, r *http.Request, objectType string) (map[string]string, bool) {
+	var body struct {
+		Properties map[string]interface{} `json:"properties"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeValidation(w, fmt.Sprintf("Invalid input JSON on line 1: %v", err), nil)
+		return nil, false
+	}
+
+	properties, details := s.validateProperties(objectType, body.Properties)
+	if len(details) > 0 {
+		writeValidation(w, validationMessage(details), details)
+		return nil, false
+	}
+	return properties, true
+}
+
+// validateProperties checks property values against the object type's
+// property definitions, returning the valid values as strings
+func (s *Server) validateProperties(objectType string, values map[string]interface{}) (map[string]string, []hubspot.ErrorDetail) {
+	definitions := make(map[string]hubspot.Property)
+	for _, prop := range s.properties[objectType] {
+		definitions[prop.Name] = prop
+	}
+
+	properties := make(map[string]string, len(values))
+	var details []hubspot.ErrorDetail
+	for _, name := range sortedKeys(values) {
+		value := stringValue(values[name])
+		def, ok := definitions[name]
+		switch {
+		case !ok:
+			details = append(details, hubspot.ErrorDetail{
+				Message: fmt.Sprintf("Property \"%s\" does not exist", name),
+				Code:    "PROPERTY_DOESNT_EXIST",
+				Context: map[string][]string{"propertyName": {name}},
+			})
+		case readOnly[name]:
+			details = append(details, hubspot.ErrorDetail{
+				Message: fmt.Sprintf("%s is a read only property; its value cannot be set.", name),
+				Code:    "READ_ONLY_VALUE",
+				Context: map[string][]string{"propertyName": {name}},
+			})
+		case value != "" && len(def.Options) > 0 && !hasOption(def, value):
+			details = append(details, hubspot.ErrorDetail{
+				Message: fmt.Sprintf("%s was not one of the allowed options: %s", value, optionValues(def)),
+				Code:    "INVALID_OPTION",
+				Context: map[string][]string{"propertyName": {name}},
+			})
+		case value != "" && def.Type == "number" && !isNumber(value):
+			details = append(details, hubspot.ErrorDetail{
+				Message: fmt.Sprintf("%s was not a valid number.", value),
+				Code:    "INVALID_INTEGER",
+				Context: map[string][]string{"propertyName": {name}},
+			})
+		default:
+			properties[name] = value
+		}
+	}
+
+	return properties, details
+}
+
+// validationMessage summarizes validation details the way HubSpot does
+func validationMessage(details []hubspot.ErrorDetail) string {
+	messages := make([]string, len(details))
+	for i, d := range details {
+		messages[i] = d.Message
+	}
+	return "Property values were not valid: " + strings.Join(messages, "; ")
+}
+
+// insert stores a new record; callers must hold s.mu
+func (s *Server) insert(objectType string, properties map[string]string) *record {
+	if _, ok := s.objects[objectType]; !ok {
+		s.objects[objectType] = make(map[int64]*record)
+	}
+
+	now := s.now().UTC()
+	rec := &record{
+		id:         s.nextID,
+		properties: make(map[string]string),
+		history:    make(map[string][]hubspot.PropertyVersion),
+		createdAt:  now,
+		updatedAt:  now,
+	}
+	s.nextID++
+
+	for name, value := range properties {
+		s.setProperty(rec, name, value, now)
+	}
+	rec.properties["hs_object_id"] = strconv.FormatInt(rec.id, 10)
+	rec.properties["createdate"] = formatTime(now)
+	rec.properties["lastmodifieddate"] = formatTime(now)
+
+	s.objects[objectType][rec.id] = rec
+	return rec
+}
+
+func (s *Server) setProperty(rec *record, name, value string, at time.Time) {
+	if old, ok := rec.properties[name]; ok && old == value {
+		return
+	}
+	rec.properties[name] = value
+	rec.history[name] = append(rec.history[name], hubspot.PropertyVersion{
+		Value:      value,
+		Timestamp:  formatTime(at),
+		SourceType: "API",
+		SourceID:   "hubspottest",
+	})
+}
+
+// remove archives a record, which is then only listed with archived=true;
+// callers must hold s.mu
+func (s *Server) remove(objectType string, rec *record) {
+	delete(s.objects[objectType], rec.id)
+	rec.archivedAt = s.now().UTC()
+	if _, ok := s.archived[objectType]; !ok {
+		s.archived[objectType] = make(map[int64]*record)
+	}
+	s.archived[objectType][rec.id] = rec
+}
+
+// lookup finds a record by ID, or by a unique property when idProperty is set
+func (s *Server) lookup(objectType, id, idProperty string) *record {
+	if idProperty != "" && idProperty != "hs_object_id" {
+		return s.findBy(objectType, idProperty, id)
+	}
+	n, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil
+	}
+	return s.objects[objectType][n]
+}
+
+func (s *Server) findBy(objectType, property, value string) *record {
+	for _, rec := range s.sorted(objectType, nil) {
+		if strings.EqualFold(rec.properties[property], value) {
+			return rec
+		}
+	}
+	return nil
+}
+
+// sorted returns the records of a type matching keep, ordered by ID
+func (s *Server) sorted(objectType string, keep func(*record) bool) []*record {
+	return sortedByID(s.objects[objectType], keep)
+}
+
+func sortedByID(recs map[int64]*record, keep func(*record) bool) []*record {
+	var records []*record
+	for _, rec := range recs {
+		if keep == nil || keep(rec) {
+			records = append(records, rec)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].id < records[j].id })
+	return records
+}
+
+// render converts a record to the API representation with the requested
+// properties, or the type's defaults when none were requested
+func (s *Server) render(objectType string, rec *record, properties []string) hubspot.Object {
+	if len(properties) == 0 {
+		properties = defaultProperties(objectType)
+	}
+
+	props := map[string]interface{}{
+		"hs_object_id":     rec.properties["hs_object_id"],
+		"createdate":       rec.properties["createdate"],
+		"lastmodifieddate": rec.properties["lastmodifieddate"],
+	}
+	for _, name := range properties {
+		if !s.defined(objectType, name) {
+			continue
+		}
+		if value, ok := rec.properties[name]; ok {
+			props[name] = value
+		} else {
+			props[name] = nil
+		}
+	}
+
+	object := hubspot.Object{
+		ID:         strconv.FormatInt(rec.id, 10),
+		Properties: props,
+		CreatedAt:  formatTime(rec.createdAt),
+		UpdatedAt:  formatTime(rec.updatedAt),
+	}
+	if !rec.archivedAt.IsZero() {
+		object.Archived = true
+		object.ArchivedAt = formatTime(rec.archivedAt)
+	}
+	return object
+}
+
+func (s *Server) defined(objectType, name string) bool {
+	for _, prop := range s.properties[objectType] {
+		if prop.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// paginate returns the page of records starting at the after cursor, which
+// is the offset of the first record, and the cursor of the next page
+func paginate(records []*record, after string, limit int) ([]*record, string, error) {
+	offset := 0
+	if after != "" {
+		n, err := strconv.Atoi(after)
+		if err != nil || n < 0 {
+			return nil, "", fmt.Errorf("Invalid after value: %s", after)
+		}
+		offset = n
+	}
+	if offset >= len(records) {
+		return nil, "", nil
+	}
+
+	end := offset + limit
+	if end >= len(records) {
+		return records[offset:], "", nil
+	}
+	return records[offset:end], strconv.Itoa(end), nil
+}
+
+func parseLimit(value string, def, max int) (int, error) {
+	if value == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("Invalid limit value: %s", value)
+	}
+	if n > max {
+		n = max
+	}
+	return n, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format("2006-01-02T15:04:05.000Z")
+}
+
+func splitList(value string) []string {
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func keys(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func isNumber(value string) bool {
+	_, err := strconv.ParseFloat(value, 64)
+	return err == nil
+}
+
+func hasOption(def hubspot.Property, value string) bool {
+	for _, opt := range def.Options {
+		if opt.Value == value {
+			return true
+		}
+	}
+	return false
+}
+
+func optionValues(def hubspot.Property) string {
+	values := make([]string, len(def.Options))
+	for i, opt := range def.Options {
+		values[i] = opt.Value
+	}
+	return strings.Join(values, ", ")
+}
@@ -0,0 +1,354 @@
+package hubspottest
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+func newTestClient(t *testing.T, s *Server) *hubspot.Client {
+	t.Helper()
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return hubspot.NewClient("test-api-key",
+		hubspot.WithBaseURL(server.URL),
+		hubspot.WithRetryPolicy(hubspot.RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond}))
+}
+
+func TestServer_CRUD(t *testing.T) {
+	client := newTestClient(t, New())
+	ctx := context.Background()
+
+	created, err := client.CreateContact(ctx, map[string]interface{}{"email": "ada@example.com", "firstname": "Ada"})
+	if err != nil {
+		t.Fatalf("CreateContact failed: %v", err)
+	}
+	if created.ID == "" || created.Properties["email"] != "ada@example.com" {
+		t.Errorf("Unexpected created contact: %+v", created)
+	}
+
+	updated, err := client.UpdateContact(ctx, created.ID, map[string]interface{}{"lifecyclestage": "customer"})
+	if err != nil {
+		t.Fatalf("UpdateContact failed: %v", err)
+	}
+	if updated.Properties["lifecyclestage"] != "customer" {
+		t.Errorf("Expected lifecyclestage customer, got %v", updated.Properties["lifecyclestage"])
+	}
+
+	got, err := client.GetContact(ctx, created.ID)
+	if err != nil {
+		t.Fatalf("GetContact failed: %v", err)
+	}
+	if got.Properties["firstname"] != "Ada" || got.Properties["lifecyclestage"] != "customer" {
+		t.Errorf("Unexpected contact: %+v", got.Properties)
+	}
+
+	if err := client.DeleteContact(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteContact failed: %v", err)
+	}
+	_, err = client.GetContact(ctx, created.ID)
+	if apiErr, ok := hubspot.AsAPIError(err); !ok || !apiErr.IsNotFound() {
+		t.Errorf("Expected not found after delete, got %v", err)
+	}
+}
+
+func TestServer_ListArchived(t *testing.T) {
+	s := New()
+	kept := s.AddObject("contacts", map[string]string{"email": "ada@example.com"})
+	gone := s.AddObject("contacts", map[string]string{"email": "grace@example.com"})
+	client := newTestClient(t, s)
+	ctx := context.Background()
+
+	if err := client.DeleteContact(ctx, gone.ID); err != nil {
+		t.Fatalf("DeleteContact failed: %v", err)
+	}
+
+	resp, err := client.ListArchivedObjects(ctx, "contacts", 10, "", nil)
+	if err != nil {
+		t.Fatalf("ListArchivedObjects failed: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != gone.ID || !resp.Results[0].Archived || resp.Results[0].ArchivedAt == "" {
+		t.Errorf("Expected only the deleted contact, archived, got %+v", resp.Results)
+	}
+
+	resp, err = client.ListObjects(ctx, "contacts", 10, "", nil)
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != kept.ID {
+		t.Errorf("Expected only the remaining contact, got %+v", resp.Results)
+	}
+}
+
+func TestServer_ValidationErrors(t *testing.T) {
+	client := newTestClient(t, New())
+	ctx := context.Background()
+
+	_, err := client.CreateContact(ctx, map[string]interface{}{"email": "a@example.com", "favorite_color": "blue", "lifecyclestage": "bogus"})
+	apiErr, ok := hubspot.AsAPIError(err)
+	if !ok || !apiErr.IsValidation() {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+	if len(apiErr.Errors) != 2 {
+		t.Fatalf("Expected 2 field errors, got %+v", apiErr.Errors)
+	}
+	codes := apiErr.Errors[0].Code + "," + apiErr.Errors[1].Code
+	if codes != "PROPERTY_DOESNT_EXIST,INVALID_OPTION" {
+		t.Errorf("Unexpected error codes %s", codes)
+	}
+	if apiErr.CorrelationID == "" {
+		t.Error("Expected a correlation ID")
+	}
+
+	if _, err := client.CreateContact(ctx, map[string]interface{}{"email": "dup@example.com"}); err != nil {
+		t.Fatalf("CreateContact failed: %v", err)
+	}
+	_, err = client.CreateContact(ctx, map[string]interface{}{"email": "DUP@example.com"})
+	if apiErr, ok := hubspot.AsAPIError(err); !ok || !apiErr.IsConflict() {
+		t.Errorf("Expected conflict for a duplicate email, got %v", err)
+	}
+}
+
+func TestServer_Pagination(t *testing.T) {
+	s := New()
+	for i := 0; i < 25; i++ {
+		s.AddObject(hubspot.Contacts.Name, map[string]string{"email": "user@example.com"})
+	}
+	client := newTestClient(t, s)
+
+	it := client.ListIterator(context.Background(), hubspot.Contacts.Name, 10, nil)
+	count := 0
+	for it.Next() {
+		count++
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iteration failed: %v", err)
+	}
+	if count != 25 {
+		t.Errorf("Expected 25 contacts, got %d", count)
+	}
+	// Three pages
+	if s.Requests() != 3 {
+		t.Errorf("Expected 3 requests, got %d", s.Requests())
+	}
+}
+
+func TestServer_Search(t *testing.T) {
+	s := New()
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"email": "ada@example.com", "lifecyclestage": "customer"})
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"email": "grace@navy.mil", "lifecyclestage": "lead"})
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"email": "alan@example.com"})
+	client := newTestClient(t, s)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		groups []hubspot.FilterGroup
+		want   int
+	}{
+		{"eq", hubspot.SimpleQuery("lifecyclestage=customer", "email"), 1},
+		{"token", hubspot.SimpleQuery("example.com", "email"), 2},
+		{"wildcard", hubspot.SimpleQuery("gr*", "email"), 1},
+		{"or", []hubspot.FilterGroup{
+			{Filters: []hubspot.Filter{{PropertyName: "lifecyclestage", Operator: "EQ", Value: "lead"}}},
+			{Filters: []hubspot.Filter{{PropertyName: "lifecyclestage", Operator: "NOT_HAS_PROPERTY"}}},
+		}, 2},
+		{"in", []hubspot.FilterGroup{{Filters: []hubspot.Filter{{PropertyName: "lifecyclestage", Operator: "IN", Values: []string{"lead", "customer"}}}}}, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.SearchObjects(ctx, hubspot.Contacts.Name, hubspot.SearchRequest{FilterGroups: tt.groups})
+			if err != nil {
+				t.Fatalf("SearchObjects failed: %v", err)
+			}
+			if resp.Total != tt.want || len(resp.Results) != tt.want {
+				t.Errorf("Expected %d results, got total %d and %d results", tt.want, resp.Total, len(resp.Results))
+			}
+		})
+	}
+
+	groups := make([]hubspot.FilterGroup, 6)
+	_, err := client.SearchObjects(ctx, hubspot.Contacts.Name, hubspot.SearchRequest{FilterGroups: groups})
+	if apiErr, ok := hubspot.AsAPIError(err); !ok || !apiErr.IsValidation() {
+		t.Errorf("Expected validation error for too many filter groups, got %v", err)
+	}
+}
+
+func TestServer_Properties(t *testing.T) {
+	client := newTestClient(t, New())
+
+	props, err := client.ListProperties(context.Background())
+	if err != nil {
+		t.Fatalf("ListProperties failed: %v", err)
+	}
+	for _, prop := range props {
+		if prop.Name == "lifecyclestage" {
+			if len(prop.Options) == 0 {
+				t.Error("Expected lifecyclestage options")
+			}
+			return
+		}
+	}
+	t.Error("lifecyclestage property not found")
+}
+
+func TestServer_History(t *testing.T) {
+	s := New()
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time {
+		clock = clock.Add(time.Minute)
+		return clock
+	}
+	client := newTestClient(t, s)
+	ctx := context.Background()
+
+	created, err := client.CreateContact(ctx, map[string]interface{}{"lifecyclestage": "lead"})
+	if err != nil {
+		t.Fatalf("CreateContact failed: %v", err)
+	}
+	if _, err := client.UpdateContact(ctx, created.ID, map[string]interface{}{"lifecyclestage": "customer"}); err != nil {
+		t.Fatalf("UpdateContact failed: %v", err)
+	}
+
+	object, err := client.GetObjectHistory(ctx, hubspot.Contacts.Name, created.ID, []string{"lifecyclestage"})
+	if err != nil {
+		t.Fatalf("GetObjectHistory failed: %v", err)
+	}
+	timeline := object.Timeline()
+	if len(timeline) != 2 || timeline[0].Value != "lead" || timeline[1].Value != "customer" {
+		t.Errorf("Unexpected timeline: %+v", timeline)
+	}
+}
+
+func TestServer_FailNextIsRetried(t *testing.T) {
+	s := New()
+	s.FailNext(2, http.StatusBadGateway)
+	client := newTestClient(t, s)
+
+	if _, err := client.ListContacts(context.Background(), 10, ""); err != nil {
+		t.Fatalf("Expected the retries to succeed, got %v", err)
+	}
+	if s.Requests() != 3 {
+		t.Errorf("Expected 3 requests, got %d", s.Requests())
+	}
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	s := New()
+	s.RateLimit = 2
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	statuses := make([]int, 3)
+	for i := range statuses {
+		req, _ := http.NewRequest("GET", server.URL+"/crm/v3/objects/contacts", nil)
+		req.Header.Set("Authorization", "Bearer test")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		statuses[i] = resp.StatusCode
+		if i == 2 && resp.Header.Get("Retry-After") != "1" {
+			t.Errorf("Expected Retry-After 1, got %q", resp.Header.Get("Retry-After"))
+		}
+	}
+	if statuses[0] != 200 || statuses[1] != 200 || statuses[2] != http.StatusTooManyRequests {
+		t.Errorf("Unexpected statuses %v", statuses)
+	}
+}
+
+func TestServer_Authorization(t *testing.T) {
+	s := New()
+	s.APIKey = "secret"
+	client := newTestClient(t, s)
+
+	_, err := client.ListContacts(context.Background(), 10, "")
+	apiErr, ok := hubspot.AsAPIError(err)
+	if !ok || !apiErr.IsUnauthorized() {
+		t.Fatalf("Expected unauthorized error, got %v", err)
+	}
+	if !strings.Contains(apiErr.Message, "Authentication credentials not found") {
+		t.Errorf("Unexpected message %q", apiErr.Message)
+	}
+}
+
+func TestServer_SearchSortsAndText(t *testing.T) {
+	s := New()
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"firstname": "Jane", "lastname": "Zimmer", "email": "jz@example.com"})
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"firstname": "Bob", "lastname": "Janeway", "email": "bob@example.com"})
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"firstname": "Janet", "lastname": "Adams", "email": "janet@example.com"})
+	s.AddObject(hubspot.Contacts.Name, map[string]string{"firstname": "Carl", "email": "carl@example.com"})
+	client := newTestClient(t, s)
+
+	resp, err := client.SearchObjects(context.Background(), hubspot.Contacts.Name, hubspot.SearchRequest{
+		Query:      "jane",
+		Sorts:      []hubspot.Sort{{PropertyName: "lastname", Direction: hubspot.SortDescending}},
+		Properties: []string{"lastname"},
+	})
+	if err != nil {
+		t.Fatalf("SearchObjects failed: %v", err)
+	}
+
+	var names []string
+	for _, object := range resp.Results {
+		names = append(names, object.Properties["lastname"].(string))
+	}
+	if strings.Join(names, ",") != "Zimmer,Janeway,Adams" {
+		t.Errorf("Unexpected results %v", names)
+	}
+}
+
+func TestServer_Batch(t *testing.T) {
+	s := New()
+	existing := s.AddObject("contacts", map[string]string{"email": "ada@example.com", "firstname": "Ada"})
+	client := newTestClient(t, s)
+	ctx := context.Background()
+
+	result, err := client.BatchUpsert(ctx, "contacts", []hubspot.BatchInput{
+		{ID: "ADA@example.com", IDProperty: "email", Properties: map[string]interface{}{"lastname": "Lovelace"}},
+		{ID: "grace@example.com", IDProperty: "email", Properties: map[string]interface{}{"firstname": "Grace"}},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsert failed: %v", err)
+	}
+	if len(result.Results) != 2 || len(result.Errors) != 0 {
+		t.Fatalf("Expected 2 results and no errors, got %+v", result)
+	}
+	if result.Results[0].ID != existing.ID || result.Results[0].New {
+		t.Errorf("Expected the existing contact to be updated, got %+v", result.Results[0])
+	}
+	if !result.Results[1].New || s.Count("contacts") != 2 {
+		t.Errorf("Expected a new contact to be created, got %+v", result.Results[1])
+	}
+
+	// An invalid value rejects the whole batch
+	result, err = client.BatchUpsert(ctx, "contacts", []hubspot.BatchInput{
+		{ID: "alan@example.com", IDProperty: "email", Properties: map[string]interface{}{"firstname": "Alan"}},
+		{ID: "kat@example.com", IDProperty: "email", Properties: map[string]interface{}{"lifecyclestage": "nope"}},
+	})
+	if err != nil {
+		t.Fatalf("BatchUpsert failed: %v", err)
+	}
+	if len(result.Errors) != 1 || len(result.Errors[0].Inputs) != 2 || s.Count("contacts") != 2 {
+		t.Errorf("Expected the batch to be rejected, got %+v", result)
+	}
+
+	read, err := client.BatchRead(ctx, "contacts", []string{"grace@example.com", "nobody@example.com"}, "email", []string{"firstname"})
+	if err != nil {
+		t.Fatalf("BatchRead failed: %v", err)
+	}
+	if len(read.Results) != 1 || read.Results[0].Properties["firstname"] != "Grace" {
+		t.Errorf("Unexpected read results: %+v", read.Results)
+	}
+	if len(read.Errors) != 1 || read.Errors[0].Category != hubspot.CategoryNotFound {
+		t.Errorf("Expected a not found error for the unknown email, got %+v", read.Errors)
+	}
+}
@@ -0,0 +1,258 @@
+// Package cassette records HTTP interactions to a file and replays them
+// later without network access, for reproducible bug reports and offline
+// regression tests.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// version is the cassette file format version
+const version = 1
+
+// redacted replaces the values of sensitive headers
+const redacted = "REDACTED"
+
+// sensitiveHeaders are never written to a cassette
+var sensitiveHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// Cassette is the content of a cassette file
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions,omitempty"`
+}
+
+// Interaction is one recorded request and its response
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is a recorded HTTP request. URL holds only the path and query, so
+// a cassette can be replayed against any base URL.
+type Request struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response
+type Response struct {
+	StatusCode int         `json:"statusCode"`
+	Headers    http.Header `json:"headers,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+// Load reads a cassette file: a header line with the version followed by
+// one interaction per line
+func Load(path string) (*Cassette, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	defer f.Close()
+
+	// The header may also hold interactions, as written before cassettes
+	// were recorded line by line
+	var c Cassette
+	dec := json.NewDecoder(f)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+	}
+	if c.Version != version {
+		return nil, fmt.Errorf("unsupported cassette version %d", c.Version)
+	}
+	for {
+		var interaction Interaction
+		if err := dec.Decode(&interaction); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to parse cassette %s: %w", path, err)
+		}
+		c.Interactions = append(c.Interactions, interaction)
+	}
+	return &c, nil
+}
+
+// appendLine appends v as a line of JSON to the file at path, creating it
+// readable only by the current user since recorded responses contain CRM
+// data
+func appendLine(path string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// Recorder is an http.RoundTripper that passes requests to an underlying
+// transport and records every interaction. Each interaction is appended to
+// the cassette file as it happens, so the file is complete even if the
+// process exits early.
+type Recorder struct {
+	path      string
+	transport http.RoundTripper
+
+	mu sync.Mutex
+}
+
+// NewRecorder creates a recorder writing to path. Interactions are appended
+// when the file already holds a cassette, so the commands of a script can be
+// recorded one after another. A nil transport means http.DefaultTransport.
+func NewRecorder(path string, transport http.RoundTripper) (*Recorder, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	if _, err := os.Stat(path); err == nil {
+		if _, err := Load(path); err != nil {
+			return nil, err
+		}
+	} else if err := appendLine(path, Cassette{Version: version}); err != nil {
+		return nil, err
+	}
+	return &Recorder{path: path, transport: transport}, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	reqBody, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := readBody(&resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: Request{
+			Method:  req.Method,
+			URL:     req.URL.RequestURI(),
+			Headers: redact(req.Header),
+			Body:    reqBody,
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Headers:    redact(resp.Header),
+			Body:       respBody,
+		},
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := appendLine(r.path, interaction); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette
+// without network access. Requests are matched on method, path, query and
+// body; identical requests, such as retries, get their recorded responses in
+// order.
+type Replayer struct {
+	mu   sync.Mutex
+	used []bool
+	c    *Cassette
+}
+
+// NewReplayer creates a replayer for a loaded cassette
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{c: c, used: make([]bool, len(c.Interactions))}
+}
+
+// ErrNoInteraction is returned for requests that are not in the cassette
+var ErrNoInteraction = errors.New("no recorded interaction")
+
+// RoundTrip implements http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(&req.Body)
+	if err != nil {
+		return nil, err
+	}
+	uri := req.URL.RequestURI()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.c.Interactions {
+		recorded := interaction.Request
+		if r.used[i] || recorded.Method != req.Method || recorded.URL != uri || recorded.Body != body {
+			continue
+		}
+		r.used[i] = true
+
+		headers := interaction.Response.Headers.Clone()
+		if headers == nil {
+			headers = make(http.Header)
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        headers,
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("%w for %s %s", ErrNoInteraction, req.Method, uri)
+}
+
+// readBody reads a request or response body and replaces it with a fresh reader
+func readBody(body *io.ReadCloser) (string, error) {
+	if *body == nil || *body == http.NoBody {
+		return "", nil
+	}
+	data, err := io.ReadAll(*body)
+	(*body).Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to read body: %w", err)
+	}
+	*body = io.NopCloser(bytes.NewReader(data))
+	return string(data), nil
+}
+
+// redact copies headers, replacing the values of sensitive ones
+func redact(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	clean := h.Clone()
+	for _, name := range sensitiveHeaders {
+		if _, ok := clean[name]; ok {
+			clean[name] = []string{redacted}
+		}
+	}
+	return clean
+}
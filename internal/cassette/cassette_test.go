@@ -0,0 +1,144 @@
+package cassette
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Set-Cookie", "session=abc")
+		w.Header().Set("X-Call", string(rune('0'+calls)))
+		if r.Method == "POST" {
+			w.WriteHeader(http.StatusCreated)
+			w.Write(body)
+			return
+		}
+		w.Write([]byte(`{"call":` + string(rune('0'+calls)) + `}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rt, err := NewRecorder(path, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	recorder := &http.Client{Transport: rt}
+
+	send := func(client *http.Client, method, url, body string) (*http.Response, string) {
+		t.Helper()
+		var reader io.Reader
+		if body != "" {
+			reader = strings.NewReader(body)
+		}
+		req, _ := http.NewRequest(method, url, reader)
+		req.Header.Set("Authorization", "Bearer pat-secret")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s failed: %v", method, url, err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return resp, string(data)
+	}
+
+	send(recorder, "GET", server.URL+"/crm/v3/objects/contacts?limit=1", "")
+	send(recorder, "GET", server.URL+"/crm/v3/objects/contacts?limit=1", "")
+
+	// A second recorder, like a second command of a script, appends
+	rt, err = NewRecorder(path, nil)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	send(&http.Client{Transport: rt}, "POST", server.URL+"/crm/v3/objects/contacts", `{"properties":{}}`)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read cassette: %v", err)
+	}
+	if strings.Contains(string(data), "pat-secret") || strings.Contains(string(data), "session=abc") {
+		t.Error("Cassette contains a secret")
+	}
+	// The header and one appended line per interaction
+	if lines := strings.Count(string(data), "\n"); lines != 4 {
+		t.Errorf("Expected 4 lines, got %d:\n%s", lines, data)
+	}
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(c.Interactions) != 3 {
+		t.Fatalf("Expected 3 interactions, got %d", len(c.Interactions))
+	}
+	if got := c.Interactions[0].Request.Headers.Get("Authorization"); got != redacted {
+		t.Errorf("Expected redacted Authorization header, got %q", got)
+	}
+
+	// Replay against a different host, without the server
+	server.Close()
+	replayer := &http.Client{Transport: NewReplayer(c)}
+	_, first := send(replayer, "GET", "http://replay.invalid/crm/v3/objects/contacts?limit=1", "")
+	_, second := send(replayer, "GET", "http://replay.invalid/crm/v3/objects/contacts?limit=1", "")
+	if first != `{"call":1}` || second != `{"call":2}` {
+		t.Errorf("Expected recorded responses in order, got %s and %s", first, second)
+	}
+
+	resp, body := send(replayer, "POST", "http://replay.invalid/crm/v3/objects/contacts", `{"properties":{}}`)
+	if resp.StatusCode != http.StatusCreated || body != `{"properties":{}}` {
+		t.Errorf("Unexpected replayed response %d %s", resp.StatusCode, body)
+	}
+
+	req, _ := http.NewRequest("GET", "http://replay.invalid/crm/v3/objects/contacts?limit=1", nil)
+	if _, err := replayer.Do(req); !errors.Is(err, ErrNoInteraction) {
+		t.Errorf("Expected ErrNoInteraction once the interactions are used up, got %v", err)
+	}
+}
+
+func TestLoad_Errors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := Load(filepath.Join(dir, "missing.json")); err == nil {
+		t.Error("Expected error for a missing cassette")
+	}
+
+	path := filepath.Join(dir, "future.json")
+	os.WriteFile(path, []byte(`{"version":99,"interactions":[]}`), 0o600)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for an unsupported version")
+	}
+
+	path = filepath.Join(dir, "truncated.json")
+	os.WriteFile(path, []byte(`{"version":1}`+"\n"+`{"request":{"method":"GET"`), 0o600)
+	if _, err := Load(path); err == nil {
+		t.Error("Expected error for a truncated interaction")
+	}
+}
+
+func TestLoad_SingleDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	os.WriteFile(path, []byte(`{
+  "version": 1,
+  "interactions": [
+    {"request": {"method": "GET", "url": "/a"}, "response": {"statusCode": 200}}
+  ]
+}
+{"request":{"method":"GET","url":"/b"},"response":{"statusCode":404}}
+`), 0o600)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(c.Interactions) != 2 || c.Interactions[0].Request.URL != "/a" || c.Interactions[1].Response.StatusCode != 404 {
+		t.Errorf("Expected the document's and the appended interactions, got %+v", c.Interactions)
+	}
+}
@@ -0,0 +1,133 @@
+package credentials
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSealOpen(t *testing.T) {
+	data, err := Seal("pat-na1-secret", "correct horse")
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+	if strings.Contains(string(data), "pat-na1-secret") {
+		t.Fatal("Sealed data contains the plaintext secret")
+	}
+
+	secret, err := Open(data, "correct horse")
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if secret != "pat-na1-secret" {
+		t.Errorf("Expected pat-na1-secret, got %s", secret)
+	}
+
+	if _, err := Open(data, "wrong"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("Expected ErrWrongPassphrase, got %v", err)
+	}
+}
+
+func TestSeal_EmptyPassphrase(t *testing.T) {
+	if _, err := Seal("secret", ""); err == nil {
+		t.Fatal("Expected error for an empty passphrase")
+	}
+}
+
+func TestOpen_IterationBounds(t *testing.T) {
+	data, err := Seal("pat-na1-secret", "passphrase")
+	if err != nil {
+		t.Fatalf("Seal failed: %v", err)
+	}
+
+	for _, iterations := range []int{1, pbkdf2Iterations - 1, maxPbkdf2Iterations + 1, 2000000000} {
+		var s sealed
+		json.Unmarshal(data, &s)
+		s.Iterations = iterations
+		edited, _ := json.Marshal(s)
+
+		start := time.Now()
+		_, err := Open(edited, "passphrase")
+		if err == nil || errors.Is(err, ErrWrongPassphrase) {
+			t.Errorf("Expected an invalid file error for %d iterations, got %v", iterations, err)
+		}
+		if elapsed := time.Since(start); elapsed > time.Second {
+			t.Errorf("Expected %d iterations to be rejected before deriving a key, took %s", iterations, elapsed)
+		}
+	}
+}
+
+func TestWriteFileReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.enc")
+	if err := WriteFile(path, "pat-na1-secret", "passphrase"); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600, got %v", info.Mode().Perm())
+	}
+
+	secret, err := ReadFile(path, "passphrase")
+	if err != nil || secret != "pat-na1-secret" {
+		t.Errorf("Expected pat-na1-secret, got %q (%v)", secret, err)
+	}
+}
+
+func TestFromCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	counter := filepath.Join(t.TempDir(), "calls")
+	command := "echo x >> " + counter + "; printf 'pat-na1-helper\\nignored\\n'"
+
+	for i := 0; i < 2; i++ {
+		secret, err := FromCommand(context.Background(), command)
+		if err != nil {
+			t.Fatalf("FromCommand failed: %v", err)
+		}
+		if secret != "pat-na1-helper" {
+			t.Errorf("Expected the first line of output, got %q", secret)
+		}
+	}
+
+	calls, err := os.ReadFile(counter)
+	if err != nil {
+		t.Fatalf("Failed to read counter: %v", err)
+	}
+	if n := strings.Count(string(calls), "x"); n != 1 {
+		t.Errorf("Expected the helper to run once, ran %d times", n)
+	}
+}
+
+func TestFromCommand_Errors(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+
+	if _, err := FromCommand(context.Background(), "echo denied >&2; exit 1"); err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Errorf("Expected helper stderr in error, got %v", err)
+	}
+	if _, err := FromCommand(context.Background(), "true"); err == nil {
+		t.Error("Expected error for empty output")
+	}
+}
+
+func TestMask(t *testing.T) {
+	if got := Mask("pat-na1-0123456789abcd"); got != "********abcd" {
+		t.Errorf("Unexpected mask %q", got)
+	}
+	if got := Mask("short"); got != "*****" {
+		t.Errorf("Unexpected mask %q", got)
+	}
+}
@@ -0,0 +1,67 @@
+// Package credentials obtains HubSpot credentials without storing them in
+// plaintext: from an external credential helper command or from a
+// passphrase-encrypted file.
+package credentials
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var (
+	cacheMu sync.Mutex
+	cache   = make(map[string]string)
+)
+
+// FromCommand runs a credential helper such as "pass show hubspot/prod"
+// through the shell and returns the first line of its output. Results are
+// cached per command for the life of the process, so the helper runs at
+// most once per invocation of hscli.
+func FromCommand(ctx context.Context, command string) (string, error) {
+	cacheMu.Lock()
+	defer cacheMu.Unlock()
+
+	if secret, ok := cache[command]; ok {
+		return secret, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("credential helper failed: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("credential helper failed: %w", err)
+	}
+
+	secret, _, _ := strings.Cut(stdout.String(), "\n")
+	secret = strings.TrimSpace(secret)
+	if secret == "" {
+		return "", errors.New("credential helper printed no credential")
+	}
+
+	cache[command] = secret
+	return secret, nil
+}
+
+// Mask hides all but the last four characters of a secret for display
+func Mask(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return strings.Repeat("*", 8) + secret[len(secret)-4:]
+}
@@ -0,0 +1,119 @@
+package credentials
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// pbkdf2Iterations follows OWASP's recommendation for PBKDF2-HMAC-SHA256
+const pbkdf2Iterations = 600000
+
+// maxPbkdf2Iterations bounds the work factor read from a credential file, so
+// an edited file can't hang the CLI
+const maxPbkdf2Iterations = 10 * pbkdf2Iterations
+
+// ErrWrongPassphrase is returned when an encrypted file cannot be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted credential file")
+
+// sealed is the on-disk format of an encrypted secret
+type sealed struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	Iterations int    `json:"iterations"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// Seal encrypts secret with a key derived from passphrase using PBKDF2 and AES-256-GCM
+func Seal(secret, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("passphrase must not be empty")
+	}
+
+	s := sealed{Version: 1, KDF: "pbkdf2-sha256", Iterations: pbkdf2Iterations, Salt: make([]byte, 16)}
+	if _, err := rand.Read(s.Salt); err != nil {
+		return nil, fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newGCM(passphrase, s.Salt, s.Iterations)
+	if err != nil {
+		return nil, err
+	}
+	s.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(s.Nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	s.Ciphertext = gcm.Seal(nil, s.Nonce, []byte(secret), nil)
+
+	return json.MarshalIndent(s, "", "  ")
+}
+
+// Open decrypts data produced by Seal
+func Open(data []byte, passphrase string) (string, error) {
+	var s sealed
+	if err := json.Unmarshal(data, &s); err != nil {
+		return "", fmt.Errorf("failed to parse credential file: %w", err)
+	}
+	if s.Version != 1 || s.KDF != "pbkdf2-sha256" {
+		return "", fmt.Errorf("unsupported credential file version %d (%s)", s.Version, s.KDF)
+	}
+	// The file can't weaken key derivation below what Seal uses
+	if s.Iterations < pbkdf2Iterations || s.Iterations > maxPbkdf2Iterations {
+		return "", fmt.Errorf("invalid credential file: %d PBKDF2 iterations, expected %d to %d",
+			s.Iterations, pbkdf2Iterations, maxPbkdf2Iterations)
+	}
+
+	gcm, err := newGCM(passphrase, s.Salt, s.Iterations)
+	if err != nil {
+		return "", err
+	}
+	if len(s.Nonce) != gcm.NonceSize() {
+		return "", ErrWrongPassphrase
+	}
+	secret, err := gcm.Open(nil, s.Nonce, s.Ciphertext, nil)
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(secret), nil
+}
+
+// WriteFile seals secret and writes it to path, readable only by the current user
+func WriteFile(path, secret, passphrase string) error {
+	data, err := Seal(secret, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write credential file: %w", err)
+	}
+	return nil
+}
+
+// ReadFile reads and decrypts a file written by WriteFile
+func ReadFile(path, passphrase string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return Open(data, passphrase)
+}
+
+func newGCM(passphrase string, salt []byte, iterations int) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, iterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,261 @@
+// Package dedupe finds duplicate contacts. Contacts are matched by email
+// address, with Gmail's dot and plus addressing normalized away, and by
+// similar names at similar companies. Matches are scored and grouped into
+// clusters for review.
+package dedupe
+
+import (
+	"math"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// Reasons why two contacts match
+const (
+	// ReasonEmail is the same email address, ignoring case
+	ReasonEmail = "email"
+	// ReasonGmail is the same Gmail address after removing dots and +tags
+	ReasonGmail = "gmail"
+	// ReasonName is a similar name at a similar company
+	ReasonName = "name+company"
+)
+
+// Scores of the email matches; name matches score their similarity
+const (
+	emailScore = 1.0
+	gmailScore = 0.95
+)
+
+// Properties are the contact properties duplicates are found by
+var Properties = []string{"email", "firstname", "lastname", "company"}
+
+// Contact is what duplicates are found by
+type Contact struct {
+	ID        string `json:"id"`
+	Email     string `json:"email"`
+	FirstName string `json:"firstname"`
+	LastName  string `json:"lastname"`
+	Company   string `json:"company"`
+}
+
+// FromObject reads the Properties of a contact
+func FromObject(object hubspot.Object) Contact {
+	get := func(name string) string {
+		value, _ := object.Properties[name].(string)
+		return strings.TrimSpace(value)
+	}
+	return Contact{
+		ID:        object.ID,
+		Email:     get("email"),
+		FirstName: get("firstname"),
+		LastName:  get("lastname"),
+		Company:   get("company"),
+	}
+}
+
+// Name returns the contact's full name
+func (c Contact) Name() string {
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+// Match is a pair of contacts that look like duplicates
+type Match struct {
+	A      string  `json:"a"`
+	B      string  `json:"b"`
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Cluster is a group of contacts connected by matches
+type Cluster struct {
+	// Score is the best score of the cluster's matches
+	Score   float64   `json:"score"`
+	Reasons []string  `json:"reasons"`
+	Members []Contact `json:"contacts"`
+	Matches []Match   `json:"matches"`
+}
+
+// Find returns the clusters of duplicate contacts, best scores first.
+// Contacts sharing an email address always match. Contacts with similar
+// names match when both their name and company similarities reach
+// minScore; the match scores 60% name and 40% company similarity.
+func Find(contacts []Contact, minScore float64) []Cluster {
+	var matches []Match
+	matched := make(map[[2]string]bool)
+	add := func(m Match) {
+		key := [2]string{m.A, m.B}
+		if m.B < m.A {
+			key = [2]string{m.B, m.A}
+		}
+		if !matched[key] {
+			matched[key] = true
+			matches = append(matches, m)
+		}
+	}
+
+	// Contacts with the same address are matched with the first of them
+	byEmail := make(map[string][]Contact)
+	var emails []string
+	for _, c := range contacts {
+		key := NormalizeEmail(c.Email)
+		if key == "" {
+			continue
+		}
+		if _, ok := byEmail[key]; !ok {
+			emails = append(emails, key)
+		}
+		byEmail[key] = append(byEmail[key], c)
+	}
+	for _, key := range emails {
+		group := byEmail[key]
+		for _, c := range group[1:] {
+			m := Match{A: group[0].ID, B: c.ID, Score: emailScore, Reason: ReasonEmail}
+			if !strings.EqualFold(group[0].Email, c.Email) {
+				m.Score, m.Reason = gmailScore, ReasonGmail
+			}
+			add(m)
+		}
+	}
+
+	// Names are only compared within blocks of the same initials, which
+	// keeps the comparisons manageable for large portals
+	type entry struct {
+		contact       Contact
+		name, company string
+	}
+	blocks := make(map[string][]entry)
+	var initials []string
+	for _, c := range contacts {
+		name, company := normalizeName(c.Name()), normalizeCompany(c.Company)
+		if !strings.Contains(name, " ") || company == "" {
+			continue
+		}
+		key := blockKey(name)
+		if _, ok := blocks[key]; !ok {
+			initials = append(initials, key)
+		}
+		blocks[key] = append(blocks[key], entry{c, name, company})
+	}
+	for _, key := range initials {
+		block := blocks[key]
+		for i := range block {
+			for j := i + 1; j < len(block); j++ {
+				a, b := block[i], block[j]
+				nameScore := Similarity(a.name, b.name)
+				companyScore := Similarity(a.company, b.company)
+				if nameScore < minScore || companyScore < minScore {
+					continue
+				}
+				add(Match{A: a.contact.ID, B: b.contact.ID, Score: round(0.6*nameScore + 0.4*companyScore), Reason: ReasonName})
+			}
+		}
+	}
+
+	return cluster(contacts, matches)
+}
+
+// cluster groups the contacts connected by matches
+func cluster(contacts []Contact, matches []Match) []Cluster {
+	parent := make(map[string]string)
+	var root func(id string) string
+	root = func(id string) string {
+		p, ok := parent[id]
+		if !ok || p == id {
+			return id
+		}
+		parent[id] = root(p)
+		return parent[id]
+	}
+	linked := make(map[string]bool)
+	for _, m := range matches {
+		linked[m.A], linked[m.B] = true, true
+		if a, b := root(m.A), root(m.B); a != b {
+			parent[a] = b
+		}
+	}
+
+	byRoot := make(map[string]*Cluster)
+	var clusters []*Cluster
+	for _, c := range contacts {
+		if !linked[c.ID] {
+			continue
+		}
+		r := root(c.ID)
+		cl, ok := byRoot[r]
+		if !ok {
+			cl = &Cluster{}
+			byRoot[r] = cl
+			clusters = append(clusters, cl)
+		}
+		cl.Members = append(cl.Members, c)
+	}
+	for _, m := range matches {
+		cl := byRoot[root(m.A)]
+		cl.Matches = append(cl.Matches, m)
+		cl.Score = math.Max(cl.Score, m.Score)
+		if !slices.Contains(cl.Reasons, m.Reason) {
+			cl.Reasons = append(cl.Reasons, m.Reason)
+		}
+	}
+
+	result := make([]Cluster, len(clusters))
+	for i, cl := range clusters {
+		sort.SliceStable(cl.Members, func(i, j int) bool { return lessID(cl.Members[i].ID, cl.Members[j].ID) })
+		sort.SliceStable(cl.Reasons, func(i, j int) bool { return reasonOrder[cl.Reasons[i]] < reasonOrder[cl.Reasons[j]] })
+		result[i] = *cl
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		a, b := result[i], result[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Members) != len(b.Members) {
+			return len(a.Members) > len(b.Members)
+		}
+		return lessID(a.Members[0].ID, b.Members[0].ID)
+	})
+	return result
+}
+
+var reasonOrder = map[string]int{ReasonEmail: 0, ReasonGmail: 1, ReasonName: 2}
+
+// NormalizeEmail lowercases an email address. Gmail addresses also lose
+// the dots and +tag of their local part, which Gmail ignores, and
+// googlemail.com becomes gmail.com.
+func NormalizeEmail(email string) string {
+	email = strings.ToLower(strings.TrimSpace(email))
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" || domain == "" {
+		return email
+	}
+	if domain != "gmail.com" && domain != "googlemail.com" {
+		return email
+	}
+	local, _, _ = strings.Cut(local, "+")
+	return strings.ReplaceAll(local, ".", "") + "@gmail.com"
+}
+
+// blockKey returns the initials of a normalized name
+func blockKey(name string) string {
+	var initials []rune
+	for _, word := range strings.Fields(name) {
+		initials = append(initials, []rune(word)[0])
+	}
+	// The first and last initials, so middle names don't split blocks
+	return string(initials[0]) + string(initials[len(initials)-1])
+}
+
+// lessID orders numeric IDs by value
+func lessID(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+	return a < b
+}
+
+func round(score float64) float64 {
+	return math.Round(score*100) / 100
+}
@@ -0,0 +1,89 @@
+package dedupe
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeEmail(t *testing.T) {
+	tests := map[string]string{
+		"Jon.Smith@Gmail.com":          "jonsmith@gmail.com",
+		"jon.smith+crm@googlemail.com": "jonsmith@gmail.com",
+		" Jon.Smith+crm@Example.com ":  "jon.smith+crm@example.com",
+		"not-an-email":                 "not-an-email",
+		"":                             "",
+	}
+	for input, want := range tests {
+		if got := NormalizeEmail(input); got != want {
+			t.Errorf("NormalizeEmail(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	if got := Similarity("martha", "marhta"); got < 0.96 || got > 0.962 {
+		t.Errorf("Expected the classic 0.961 for martha/marhta, got %f", got)
+	}
+	if got := Similarity("abc", "xyz"); got != 0 {
+		t.Errorf("Expected 0 for unrelated strings, got %f", got)
+	}
+	if got := Similarity("acme", "acme"); got != 1 {
+		t.Errorf("Expected 1 for equal strings, got %f", got)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	if got := normalizeName("  José O'Neil "); got != "jose o neil" {
+		t.Errorf("Unexpected name %q", got)
+	}
+	if got := normalizeCompany("Acme, Inc."); got != "acme" {
+		t.Errorf("Unexpected company %q", got)
+	}
+}
+
+func TestFind(t *testing.T) {
+	contacts := []Contact{
+		{ID: "1", Email: "Jon.Smith@Gmail.com", FirstName: "Jon", LastName: "Smith", Company: "Acme"},
+		{ID: "2", Email: "jon.smith@gmail.com"},
+		{ID: "3", Email: "jonsmith+news@gmail.com"},
+		{ID: "4", Email: "ada@enigma.example.com", FirstName: "Ada", LastName: "Lovelace", Company: "Enigma Labs"},
+		{ID: "5", Email: "a.lovelace@other.example.com", FirstName: "Ada", LastName: "Lovelace", Company: "Enigma Labs Ltd"},
+		{ID: "6", Email: "ada@globex.example.com", FirstName: "Ada", LastName: "Lovelace", Company: "Globex"},
+		{ID: "7", Email: "grace@example.com", FirstName: "Grace", LastName: "Hopper", Company: "Acme"},
+		{ID: "10", Email: "JON.SMITH@gmail.com"},
+	}
+
+	clusters := Find(contacts, 0.85)
+	if len(clusters) != 2 {
+		t.Fatalf("Expected 2 clusters, got %+v", clusters)
+	}
+
+	jon := clusters[0]
+	if jon.Score != 1 || !reflect.DeepEqual(jon.Reasons, []string{ReasonEmail, ReasonGmail}) {
+		t.Errorf("Expected an email and gmail cluster scoring 1, got %v %v", jon.Score, jon.Reasons)
+	}
+	var ids []string
+	for _, c := range jon.Members {
+		ids = append(ids, c.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"1", "2", "3", "10"}) {
+		t.Errorf("Expected contacts 1, 2, 3 and 10, got %v", ids)
+	}
+	for _, m := range jon.Matches {
+		if m.B == "3" && (m.Reason != ReasonGmail || m.Score != gmailScore) {
+			t.Errorf("Expected the +tag address to be a gmail match, got %+v", m)
+		}
+	}
+
+	ada := clusters[1]
+	if len(ada.Members) != 2 || ada.Members[0].ID != "4" || ada.Members[1].ID != "5" {
+		t.Errorf("Expected contacts 4 and 5 without the Globex one, got %+v", ada.Members)
+	}
+	if ada.Score != 1 || ada.Reasons[0] != ReasonName {
+		t.Errorf("Expected a name+company match scoring 1, got %v %v", ada.Score, ada.Reasons)
+	}
+
+	if clusters := Find(contacts[3:7], 1.01); len(clusters) != 0 {
+		t.Errorf("Expected no name matches above the minimum score, got %+v", clusters)
+	}
+}
@@ -0,0 +1,216 @@
+// Package export writes every object of a type to a file page by page,
+// keeping a checkpoint so an interrupted export can be resumed where it
+// stopped instead of starting over.
+package export
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// Checkpoint records the progress of an export. It is saved next to the
+// output file after every page and holds the count and checksum of the
+// file once the export is complete.
+type Checkpoint struct {
+	ObjectType string   `json:"objectType"`
+	Format     string   `json:"format"`
+	Properties []string `json:"properties"`
+	// After is the cursor of the next page to fetch
+	After string `json:"after,omitempty"`
+	// Pages is the number of pages written
+	Pages int `json:"pages"`
+	// Count is the number of objects written
+	Count int `json:"count"`
+	// Size is the length of the output file after the last complete page
+	Size      int64     `json:"size"`
+	Complete  bool      `json:"complete"`
+	SHA256    string    `json:"sha256,omitempty"`
+	StartedAt time.Time `json:"startedAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CheckpointPath returns where the checkpoint of an export to out is kept
+func CheckpointPath(out string) string {
+	return out + ".checkpoint.json"
+}
+
+// LoadCheckpoint reads a checkpoint, returning nil if there is none
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint %s: %w", path, err)
+	}
+	return &cp, nil
+}
+
+// Save writes the checkpoint atomically, so a crash never leaves a torn file
+func (cp *Checkpoint) Save(path string) error {
+	cp.UpdatedAt = time.Now().UTC()
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Fetched reports whether every page has been written, so only the
+// checksum is left to do
+func (cp *Checkpoint) Fetched() bool {
+	return cp.Pages > 0 && cp.After == ""
+}
+
+// Create creates the output file of a new export and saves its first
+// checkpoint, so that the export can be resumed even if its first page fails
+func Create(out string, cp *Checkpoint) (*os.File, error) {
+	f, err := os.Create(out)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create export file: %w", err)
+	}
+	if err := cp.Save(CheckpointPath(out)); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return f, nil
+}
+
+// Reopen opens the output file of an interrupted export for writing at the
+// end of the last complete page, dropping whatever was written after it
+func Reopen(out string, cp *Checkpoint) (*os.File, error) {
+	f, err := os.OpenFile(out, os.O_RDWR, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+	if info.Size() < cp.Size {
+		f.Close()
+		return nil, fmt.Errorf("%s is shorter than its checkpoint says, it was changed since the export stopped", out)
+	}
+
+	if err := f.Truncate(cp.Size); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to truncate export file: %w", err)
+	}
+	if _, err := f.Seek(cp.Size, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to open export file: %w", err)
+	}
+	return f, nil
+}
+
+// Writer writes exported objects to the output file
+type Writer interface {
+	Write(object hubspot.Object) error
+	// Close terminates the output, e.g. writes the header of an empty CSV file
+	Close() error
+}
+
+// Run writes the iterator's objects to out through w, syncing out and
+// saving the checkpoint at cpPath after each page. out must be positioned at cp.Size. progress,
+// if set, is called with the running count after each page. When every
+// page has been written, the file's checksum is recorded and the
+// checkpoint marked complete.
+func Run(it *hubspot.Iterator, out *os.File, w Writer, cp *Checkpoint, cpPath string, progress func(count int)) error {
+	count := cp.Count
+	for !cp.Fetched() && it.Next() {
+		if err := w.Write(it.Object()); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		count++
+		if !it.PageDone() {
+			continue
+		}
+
+		// The checkpoint must not count bytes that could be lost in a crash
+		if err := out.Sync(); err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		size, err := out.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return fmt.Errorf("failed to write export: %w", err)
+		}
+		cp.After, cp.Pages, cp.Count, cp.Size = it.Cursor(), cp.Pages+1, count, size
+		if err := cp.Save(cpPath); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(count)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	// An empty export never completes a page
+	if cp.Pages == 0 {
+		cp.Pages, cp.After = 1, ""
+	}
+
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	if err := out.Sync(); err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+	sum, err := Checksum(out.Name())
+	if err != nil {
+		return err
+	}
+	cp.SHA256, cp.Complete = sum, true
+	return cp.Save(cpPath)
+}
+
+// Checksum returns the hex SHA-256 digest of a file
+func Checksum(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
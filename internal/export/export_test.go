@@ -0,0 +1,205 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/obay/hscli/hubspottest"
+	"github.com/obay/hscli/internal/hubspot"
+	"github.com/obay/hscli/internal/output"
+)
+
+// csvWriter writes objects as CSV and fails after failAfter objects, if set
+type csvWriter struct {
+	enc       *output.Encoder
+	written   int
+	failAfter int
+}
+
+func (w *csvWriter) Write(object hubspot.Object) error {
+	if w.failAfter > 0 && w.written == w.failAfter {
+		return errors.New("disk full")
+	}
+	w.written++
+	email, _ := object.Properties["email"].(string)
+	return w.enc.Encode(object, []string{object.ID, email})
+}
+
+func (w *csvWriter) Close() error {
+	return w.enc.Close()
+}
+
+func newCSVWriter(f *os.File, cp *Checkpoint, failAfter int) *csvWriter {
+	enc := output.NewEncoder(f, output.CSV, []string{"id", "email"})
+	if cp.Size > 0 {
+		enc.SkipHeader()
+	}
+	return &csvWriter{enc: enc, failAfter: failAfter}
+}
+
+func TestRun_Resume(t *testing.T) {
+	mock := hubspottest.New()
+	for i := 0; i < 250; i++ {
+		mock.AddObject("contacts", map[string]string{"email": fmt.Sprintf("lead%03d@example.com", i)})
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := hubspot.NewClient("test", hubspot.WithBaseURL(server.URL))
+	ctx := context.Background()
+	properties := []string{"email"}
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "contacts.csv")
+	cpPath := CheckpointPath(out)
+
+	// The first run stops halfway through the second page
+	f, err := os.Create(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := &Checkpoint{ObjectType: "contacts", Format: output.CSV, Properties: properties}
+	it := client.ListIterator(ctx, "contacts", 100, properties)
+	err = Run(it, f, newCSVWriter(f, cp, 150), cp, cpPath, nil)
+	f.Close()
+	if err == nil || !strings.Contains(err.Error(), "disk full") {
+		t.Fatalf("Expected the write error, got %v", err)
+	}
+
+	cp, err = LoadCheckpoint(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Pages != 1 || cp.Count != 100 || cp.After == "" || cp.Complete {
+		t.Fatalf("Expected a checkpoint after the first page, got %+v", cp)
+	}
+
+	// The resumed run drops the partial page and continues from the cursor
+	f, err = Reopen(out, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	it = client.ListIteratorFrom(ctx, "contacts", 100, cp.Properties, cp.After)
+	if err := Run(it, f, newCSVWriter(f, cp, 0), cp, cpPath, nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	f.Close()
+
+	if !cp.Complete || cp.Count != 250 || cp.Pages != 3 {
+		t.Errorf("Expected a complete export of 250 contacts in 3 pages, got %+v", cp)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 251 || lines[0] != "id,email" {
+		t.Fatalf("Expected a header and 250 rows, got %d lines starting with %q", len(lines), lines[0])
+	}
+	for i, line := range lines[1:] {
+		if !strings.HasSuffix(line, fmt.Sprintf(",lead%03d@example.com", i)) {
+			t.Fatalf("Expected row %d to be lead%03d, got %q", i+1, i, line)
+		}
+	}
+
+	sum, err := Checksum(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved, err := LoadCheckpoint(cpPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.SHA256 != sum || !saved.Complete || saved.Count != 250 {
+		t.Errorf("Expected the saved checkpoint to record the count and checksum %s, got %+v", sum, saved)
+	}
+}
+
+func TestRun_Empty(t *testing.T) {
+	server := httptest.NewServer(hubspottest.New())
+	defer server.Close()
+	client := hubspot.NewClient("test", hubspot.WithBaseURL(server.URL))
+
+	out := filepath.Join(t.TempDir(), "contacts.csv")
+	f, err := os.Create(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	cp := &Checkpoint{ObjectType: "contacts", Format: output.CSV}
+	it := client.ListIterator(context.Background(), "contacts", 100, nil)
+	if err := Run(it, f, newCSVWriter(f, cp, 0), cp, CheckpointPath(out), nil); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	data, _ := os.ReadFile(out)
+	if string(data) != "id,email\n" || !cp.Complete || cp.Count != 0 {
+		t.Errorf("Expected a complete export with only the header, got %q and %+v", data, cp)
+	}
+}
+
+func TestCreate_ResumeFirstPage(t *testing.T) {
+	mock := hubspottest.New()
+	for i := 0; i < 5; i++ {
+		mock.AddObject("contacts", map[string]string{"email": fmt.Sprintf("lead%d@example.com", i)})
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := hubspot.NewClient("test", hubspot.WithBaseURL(server.URL), hubspot.WithRetryPolicy(hubspot.RetryPolicy{}))
+	ctx := context.Background()
+
+	out := filepath.Join(t.TempDir(), "contacts.csv")
+	cp := &Checkpoint{ObjectType: "contacts", Format: output.CSV, Properties: []string{"email"}}
+	f, err := Create(out, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock.FailNext(1, 400)
+	err = Run(client.ListIterator(ctx, "contacts", 100, cp.Properties), f, newCSVWriter(f, cp, 0), cp, CheckpointPath(out), nil)
+	f.Close()
+	if err == nil {
+		t.Fatal("Expected the first page to fail")
+	}
+
+	cp, err = LoadCheckpoint(CheckpointPath(out))
+	if err != nil || cp == nil {
+		t.Fatalf("Expected a checkpoint to resume from, got %v", err)
+	}
+	f, err = Reopen(out, cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	if err := Run(client.ListIteratorFrom(ctx, "contacts", 100, cp.Properties, cp.After), f, newCSVWriter(f, cp, 0), cp, CheckpointPath(out), nil); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	data, _ := os.ReadFile(out)
+	if lines := strings.Count(string(data), "\n"); lines != 6 || !cp.Complete {
+		t.Errorf("Expected a header and 5 rows, got %d lines and %+v", lines, cp)
+	}
+}
+
+func TestReopen_ChangedFile(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "contacts.ndjson")
+	if err := os.WriteFile(out, []byte("{}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Reopen(out, &Checkpoint{Size: 100}); err == nil {
+		t.Error("Expected an error for a file shorter than its checkpoint")
+	}
+}
+
+func TestLoadCheckpoint_Missing(t *testing.T) {
+	cp, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.checkpoint.json"))
+	if cp != nil || err != nil {
+		t.Errorf("Expected no checkpoint and no error, got %+v and %v", cp, err)
+	}
+}
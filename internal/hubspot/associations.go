@@ -0,0 +1,156 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Association categories
+const (
+	AssociationCategoryHubSpot     = "HUBSPOT_DEFINED"
+	AssociationCategoryUser        = "USER_DEFINED"
+	AssociationCategoryIntegration = "INTEGRATOR_DEFINED"
+)
+
+// AssociationType identifies an association type, optionally with a label
+type AssociationType struct {
+	Category string `json:"category"`
+	TypeID   int    `json:"typeId"`
+	Label    string `json:"label,omitempty"`
+}
+
+// Association represents a link from an object to another object
+type Association struct {
+	ToObjectID       int64             `json:"toObjectId"`
+	AssociationTypes []AssociationType `json:"associationTypes"`
+}
+
+// AssociationsResponse represents a page of associations
+type AssociationsResponse struct {
+	Results []Association `json:"results"`
+	Paging  *Paging       `json:"paging,omitempty"`
+}
+
+// AssociationLabelsResponse represents the association types defined between two object types
+type AssociationLabelsResponse struct {
+	Results []AssociationType `json:"results"`
+}
+
+// AssociatedObject represents an associated object ID returned by v3 object reads
+type AssociatedObject struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// AssociatedObjects represents the associations of one object type in a v3 object read
+type AssociatedObjects struct {
+	Results []AssociatedObject `json:"results"`
+}
+
+// associationsEndpoint returns the v4 associations endpoint between an object and an object type
+func associationsEndpoint(fromType, objectID, toType string, segments ...string) string {
+	endpoint := fmt.Sprintf("/crm/v4/objects/%s/%s/associations/%s",
+		url.PathEscape(fromType), url.PathEscape(objectID), url.PathEscape(toType))
+	for _, segment := range segments {
+		endpoint += "/" + url.PathEscape(segment)
+	}
+	return endpoint
+}
+
+// ListAssociations retrieves all associations from an object to objects of toType
+func (c *Client) ListAssociations(ctx context.Context, fromType, objectID, toType string) ([]Association, error) {
+	var associations []Association
+	after := ""
+
+	for {
+		params := url.Values{}
+		params.Add("limit", "500")
+		if after != "" {
+			params.Add("after", after)
+		}
+
+		respBody, err := c.doRequest(ctx, "GET", associationsEndpoint(fromType, objectID, toType)+"?"+params.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var assocResp AssociationsResponse
+		if err := json.Unmarshal(respBody, &assocResp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+
+		associations = append(associations, assocResp.Results...)
+
+		if assocResp.Paging == nil || assocResp.Paging.Next == nil || assocResp.Paging.Next.After == "" {
+			return associations, nil
+		}
+		after = assocResp.Paging.Next.After
+	}
+}
+
+// Associate links an object to another object. Without association types the
+// default (unlabeled) association is created.
+func (c *Client) Associate(ctx context.Context, fromType, objectID, toType, toObjectID string, types ...AssociationType) error {
+	if len(types) == 0 {
+		endpoint := fmt.Sprintf("/crm/v4/objects/%s/%s/associations/default/%s/%s",
+			url.PathEscape(fromType), url.PathEscape(objectID), url.PathEscape(toType), url.PathEscape(toObjectID))
+		_, err := c.doRequest(ctx, "PUT", endpoint, nil)
+		return err
+	}
+
+	body := make([]map[string]interface{}, len(types))
+	for i, t := range types {
+		body[i] = map[string]interface{}{
+			"associationCategory": t.Category,
+			"associationTypeId":   t.TypeID,
+		}
+	}
+
+	_, err := c.doRequest(ctx, "PUT", associationsEndpoint(fromType, objectID, toType, toObjectID), body)
+	return err
+}
+
+// Disassociate removes all associations between two objects
+func (c *Client) Disassociate(ctx context.Context, fromType, objectID, toType, toObjectID string) error {
+	_, err := c.doRequest(ctx, "DELETE", associationsEndpoint(fromType, objectID, toType, toObjectID), nil)
+	return err
+}
+
+// ListAssociationLabels retrieves the association types defined between two object types
+func (c *Client) ListAssociationLabels(ctx context.Context, fromType, toType string) ([]AssociationType, error) {
+	endpoint := fmt.Sprintf("/crm/v4/associations/%s/%s/labels", url.PathEscape(fromType), url.PathEscape(toType))
+
+	respBody, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var labelsResp AssociationLabelsResponse
+	if err := json.Unmarshal(respBody, &labelsResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return labelsResp.Results, nil
+}
+
+// ResolveAssociationLabel finds the association type between two object
+// types by its label (case-insensitive) or numeric type ID
+func (c *Client) ResolveAssociationLabel(ctx context.Context, fromType, toType, label string) (AssociationType, error) {
+	labels, err := c.ListAssociationLabels(ctx, fromType, toType)
+	if err != nil {
+		return AssociationType{}, fmt.Errorf("failed to list association labels: %w", err)
+	}
+
+	typeID, numErr := strconv.Atoi(label)
+	for _, t := range labels {
+		if (t.Label != "" && strings.EqualFold(t.Label, label)) || (numErr == nil && t.TypeID == typeID) {
+			return t, nil
+		}
+	}
+
+	return AssociationType{}, fmt.Errorf("unknown association label %q between %s and %s", label, fromType, toType)
+}
@@ -0,0 +1,85 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListAssociations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crm/v4/objects/contacts/1/associations/companies" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if r.URL.Query().Get("after") == "" {
+			w.Write([]byte(`{"results":[{"toObjectId":10,"associationTypes":[{"category":"HUBSPOT_DEFINED","typeId":1,"label":"Primary"}]}],"paging":{"next":{"after":"x"}}}`))
+			return
+		}
+		w.Write([]byte(`{"results":[{"toObjectId":11,"associationTypes":[{"category":"HUBSPOT_DEFINED","typeId":279}]}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	associations, err := client.ListAssociations(context.Background(), "contacts", "1", "companies")
+	if err != nil {
+		t.Fatalf("ListAssociations failed: %v", err)
+	}
+	if len(associations) != 2 || associations[0].ToObjectID != 10 || associations[0].AssociationTypes[0].Label != "Primary" {
+		t.Errorf("Unexpected associations: %+v", associations)
+	}
+}
+
+func TestClient_AssociateWithLabel(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/crm/v4/associations/contacts/companies/labels":
+			w.Write([]byte(`{"results":[{"category":"HUBSPOT_DEFINED","typeId":1,"label":"Primary"},{"category":"USER_DEFINED","typeId":42,"label":"Billing contact"}]}`))
+		case r.Method == "PUT" && r.URL.Path == "/crm/v4/objects/contacts/1/associations/companies/10":
+			var body []map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			if len(body) != 1 || body[0]["associationCategory"] != "USER_DEFINED" || body[0]["associationTypeId"] != float64(42) {
+				t.Errorf("Unexpected association body: %v", body)
+			}
+			w.Write([]byte(`{}`))
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	label, err := client.ResolveAssociationLabel(context.Background(), "contacts", "companies", "billing CONTACT")
+	if err != nil {
+		t.Fatalf("ResolveAssociationLabel failed: %v", err)
+	}
+	if err := client.Associate(context.Background(), "contacts", "1", "companies", "10", label); err != nil {
+		t.Fatalf("Associate failed: %v", err)
+	}
+}
+
+func TestClient_GetObjectWithAssociations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("associations"); got != "companies,deals" {
+			t.Errorf("Unexpected associations parameter %q", got)
+		}
+		w.Write([]byte(`{"id":"1","properties":{},"associations":{"companies":{"results":[{"id":"10","type":"contact_to_company"}]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	object, err := client.GetObjectWithAssociations(context.Background(), "contacts", "1", nil, []string{"companies", "deals"})
+	if err != nil {
+		t.Fatalf("GetObjectWithAssociations failed: %v", err)
+	}
+	if ids := object.Associations["companies"].Results; len(ids) != 1 || ids[0].ID != "10" {
+		t.Errorf("Unexpected associations: %+v", object.Associations)
+	}
+}
@@ -0,0 +1,202 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MaxBatchSize is the number of records HubSpot accepts in a single batch request
+const MaxBatchSize = 100
+
+// BatchInput represents a single record in a batch request. Which fields are
+// used depends on the operation: reads and archives only use ID, creates only
+// use Properties, and upserts identify records by IDProperty (e.g. "email").
+type BatchInput struct {
+	ID         string                 `json:"id,omitempty"`
+	IDProperty string                 `json:"idProperty,omitempty"`
+	Properties map[string]interface{} `json:"properties,omitempty"`
+}
+
+// BatchError describes records that failed in a batch operation
+type BatchError struct {
+	Status      string              `json:"status"`
+	Category    string              `json:"category"`
+	SubCategory string              `json:"subCategory,omitempty"`
+	Message     string              `json:"message"`
+	Context     map[string][]string `json:"context,omitempty"`
+	// Inputs holds the indexes into the caller's inputs that this error applies
+	// to, when they can be determined
+	Inputs []int `json:"-"`
+}
+
+// BatchResult collects the successful records and per-record failures of a batch operation
+type BatchResult struct {
+	Results []Object     `json:"results"`
+	Errors  []BatchError `json:"errors,omitempty"`
+}
+
+// batchResponse represents the response of a single batch request, which uses
+// status 207 when some records failed. Its status field is left out: the
+// object batch endpoints are synchronous and always report COMPLETE.
+type batchResponse struct {
+	Results []Object     `json:"results"`
+	Errors  []BatchError `json:"errors,omitempty"`
+}
+
+// BatchRead retrieves objects by ID, or by the value of idProperty when it is set
+func (c *Client) BatchRead(ctx context.Context, objectType string, ids []string, idProperty string, properties []string) (*BatchResult, error) {
+	inputs := make([]BatchInput, len(ids))
+	for i, id := range ids {
+		inputs[i] = BatchInput{ID: id}
+	}
+
+	return c.batch(ctx, objectType, "read", inputs, func(chunk []BatchInput) interface{} {
+		body := map[string]interface{}{
+			"inputs":     idInputs(chunk),
+			"properties": properties,
+		}
+		if idProperty != "" {
+			body["idProperty"] = idProperty
+		}
+		return body
+	})
+}
+
+// BatchCreate creates objects from the properties of each input
+func (c *Client) BatchCreate(ctx context.Context, objectType string, inputs []BatchInput) (*BatchResult, error) {
+	return c.batch(ctx, objectType, "create", inputs, func(chunk []BatchInput) interface{} {
+		creates := make([]map[string]interface{}, len(chunk))
+		for i, input := range chunk {
+			creates[i] = map[string]interface{}{"properties": input.Properties}
+		}
+		return map[string]interface{}{"inputs": creates}
+	})
+}
+
+// BatchUpdate updates the properties of existing objects identified by ID
+func (c *Client) BatchUpdate(ctx context.Context, objectType string, inputs []BatchInput) (*BatchResult, error) {
+	return c.batch(ctx, objectType, "update", inputs, inputsBody)
+}
+
+// BatchUpsert creates or updates objects identified by the value of their IDProperty
+func (c *Client) BatchUpsert(ctx context.Context, objectType string, inputs []BatchInput) (*BatchResult, error) {
+	return c.batch(ctx, objectType, "upsert", inputs, inputsBody)
+}
+
+// BatchArchive archives objects by ID
+func (c *Client) BatchArchive(ctx context.Context, objectType string, ids []string) (*BatchResult, error) {
+	inputs := make([]BatchInput, len(ids))
+	for i, id := range ids {
+		inputs[i] = BatchInput{ID: id}
+	}
+
+	return c.batch(ctx, objectType, "archive", inputs, func(chunk []BatchInput) interface{} {
+		return map[string]interface{}{"inputs": idInputs(chunk)}
+	})
+}
+
+func inputsBody(chunk []BatchInput) interface{} {
+	return map[string]interface{}{"inputs": chunk}
+}
+
+func idInputs(chunk []BatchInput) []map[string]string {
+	ids := make([]map[string]string, len(chunk))
+	for i, input := range chunk {
+		ids[i] = map[string]string{"id": input.ID}
+	}
+	return ids
+}
+
+// batch sends inputs in chunks of MaxBatchSize. A chunk rejected as a whole
+// (e.g. for an invalid property) is reported as a BatchError covering all of
+// its records and the remaining chunks are still sent. Errors that would
+// affect every chunk, such as authentication failures or cancellation, stop
+// the operation and are returned along with the results collected so far.
+func (c *Client) batch(ctx context.Context, objectType, action string, inputs []BatchInput, body func([]BatchInput) interface{}) (*BatchResult, error) {
+	result := &BatchResult{Results: []Object{}}
+	endpoint := objectsEndpoint(objectType, "batch", action)
+
+	for start := 0; start < len(inputs); start += MaxBatchSize {
+		end := start + MaxBatchSize
+		if end > len(inputs) {
+			end = len(inputs)
+		}
+		chunk := inputs[start:end]
+
+		respBody, err := c.doRequest(ctx, "POST", endpoint, body(chunk))
+		if err != nil {
+			apiErr, ok := AsAPIError(err)
+			if !ok || !chunkError(apiErr) {
+				return result, err
+			}
+			result.Errors = append(result.Errors, BatchError{
+				Status:      apiErr.Status,
+				Category:    apiErr.Category,
+				SubCategory: apiErr.SubCategory,
+				Message:     apiErr.Message,
+				Context:     apiErr.Context,
+				Inputs:      indexRange(start, end),
+			})
+			continue
+		}
+
+		// Archive responds with 204 No Content
+		if len(respBody) == 0 {
+			continue
+		}
+
+		var batchResp batchResponse
+		if err := json.Unmarshal(respBody, &batchResp); err != nil {
+			return result, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+
+		result.Results = append(result.Results, batchResp.Results...)
+		for _, batchErr := range batchResp.Errors {
+			batchErr.Inputs = matchInputs(batchErr, inputs, start, end)
+			result.Errors = append(result.Errors, batchErr)
+		}
+	}
+
+	return result, nil
+}
+
+// chunkError reports whether an API error only concerns the records of one chunk
+func chunkError(apiErr *APIError) bool {
+	switch apiErr.StatusCode {
+	case http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity:
+		return true
+	}
+	return false
+}
+
+// matchInputs maps the IDs listed in a batch error's context back to input indexes
+func matchInputs(batchErr BatchError, inputs []BatchInput, start, end int) []int {
+	ids := make(map[string]bool)
+	for _, key := range []string{"ids", "id"} {
+		for _, id := range batchErr.Context[key] {
+			ids[strings.ToLower(id)] = true
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	var matched []int
+	for i := start; i < end; i++ {
+		if inputs[i].ID != "" && ids[strings.ToLower(inputs[i].ID)] {
+			matched = append(matched, i)
+		}
+	}
+	return matched
+}
+
+func indexRange(start, end int) []int {
+	indexes := make([]int, 0, end-start)
+	for i := start; i < end; i++ {
+		indexes = append(indexes, i)
+	}
+	return indexes
+}
@@ -0,0 +1,117 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_BatchUpsertChunks(t *testing.T) {
+	var sizes []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crm/v3/objects/contacts/batch/upsert" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		var body struct {
+			Inputs []BatchInput `json:"inputs"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		sizes = append(sizes, len(body.Inputs))
+
+		if body.Inputs[0].IDProperty != "email" {
+			t.Errorf("Expected idProperty email, got %q", body.Inputs[0].IDProperty)
+		}
+
+		results := make([]Object, len(body.Inputs))
+		for i, input := range body.Inputs {
+			results[i] = Object{ID: fmt.Sprint(i), Properties: input.Properties, New: true}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"status": "COMPLETE", "results": results})
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	inputs := make([]BatchInput, 250)
+	for i := range inputs {
+		email := fmt.Sprintf("user%d@example.com", i)
+		inputs[i] = BatchInput{ID: email, IDProperty: "email", Properties: map[string]interface{}{"email": email}}
+	}
+
+	result, err := client.BatchUpsert(context.Background(), Contacts.Name, inputs)
+	if err != nil {
+		t.Fatalf("BatchUpsert failed: %v", err)
+	}
+	if len(sizes) != 3 || sizes[0] != 100 || sizes[2] != 50 {
+		t.Errorf("Expected chunks of 100, 100, 50, got %v", sizes)
+	}
+	if len(result.Results) != 250 || !result.Results[0].New {
+		t.Errorf("Expected 250 new results, got %d", len(result.Results))
+	}
+}
+
+func TestClient_BatchReadPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMultiStatus)
+		w.Write([]byte(`{
+			"status": "COMPLETE",
+			"results": [{"id": "1", "properties": {}}],
+			"numErrors": 1,
+			"errors": [{
+				"status": "error",
+				"category": "OBJECT_NOT_FOUND",
+				"message": "Could not get some CONTACT objects, they may be deleted or not exist.",
+				"context": {"ids": ["2"]}
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	result, err := client.BatchRead(context.Background(), Contacts.Name, []string{"1", "2"}, "", []string{"email"})
+	if err != nil {
+		t.Fatalf("BatchRead failed: %v", err)
+	}
+	if len(result.Results) != 1 || len(result.Errors) != 1 {
+		t.Fatalf("Expected one result and one error, got %+v", result)
+	}
+	if inputs := result.Errors[0].Inputs; len(inputs) != 1 || inputs[0] != 1 {
+		t.Errorf("Expected the error to point at input 1, got %v", inputs)
+	}
+}
+
+func TestClient_BatchCreateRejectedChunk(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"error","category":"VALIDATION_ERROR","message":"Property \"foo\" does not exist"}`))
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"status":"COMPLETE","results":[{"id":"101"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	inputs := make([]BatchInput, 101)
+	result, err := client.BatchCreate(context.Background(), Contacts.Name, inputs)
+	if err != nil {
+		t.Fatalf("BatchCreate failed: %v", err)
+	}
+	if len(result.Errors) != 1 || len(result.Errors[0].Inputs) != 100 || result.Errors[0].Category != CategoryValidation {
+		t.Errorf("Expected the first chunk to be rejected, got %+v", result.Errors)
+	}
+	if len(result.Results) != 1 {
+		t.Errorf("Expected the second chunk to succeed, got %d results", len(result.Results))
+	}
+}
@@ -2,11 +2,11 @@ package hubspot
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 )
@@ -18,34 +18,73 @@ const (
 // Client represents a HubSpot API client
 type Client struct {
 	apiKey  string
+	tokens  TokenSource
 	baseURL string
 	client  *http.Client
+	retry   RetryPolicy
+	limiter *rateLimiter
+	sleep   func(context.Context, time.Duration) error
+	// replay is set when responses come from a recording
+	replay bool
+}
+
+// Option configures optional Client settings
+type Option func(*Client)
+
+// WithRetryPolicy sets the policy used to retry throttled and failed requests
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// WithBaseURL sends requests to a different API host, such as a mock server
+func WithBaseURL(url string) Option {
+	return func(c *Client) {
+		c.baseURL = strings.TrimRight(url, "/")
+	}
+}
+
+// WithTransport sends requests through rt, e.g. to record or replay them
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.client.Transport = rt
+	}
+}
+
+// WithReplay answers requests through rt from recorded responses. Throttled
+// responses are retried without waiting, since nothing is throttled, and
+// transport errors such as a request missing from the recording fail at once.
+func WithReplay(rt http.RoundTripper) Option {
+	return func(c *Client) {
+		c.client.Transport = rt
+		c.replay = true
+	}
 }
 
 // NewClient creates a new HubSpot API client
-func NewClient(apiKey string) *Client {
-	return &Client{
+func NewClient(apiKey string, opts ...Option) *Client {
+	c := &Client{
 		apiKey:  apiKey,
 		baseURL: baseURL,
 		client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		retry:   DefaultRetryPolicy(),
+		limiter: &rateLimiter{},
+		sleep:   sleepContext,
+	}
+	for _, opt := range opts {
+		opt(c)
 	}
+	return c
 }
 
 // Contact represents a HubSpot contact
-type Contact struct {
-	ID         string                 `json:"id"`
-	Properties map[string]interface{} `json:"properties"`
-	CreatedAt  string                 `json:"createdAt"`
-	UpdatedAt  string                 `json:"updatedAt"`
-}
+type Contact = Object
 
 // ContactResponse represents the response from HubSpot API
-type ContactResponse struct {
-	Results []Contact `json:"results"`
-	Paging  *Paging   `json:"paging,omitempty"`
-}
+type ContactResponse = ObjectResponse
 
 // Paging represents pagination information
 type Paging struct {
@@ -57,17 +96,34 @@ type NextPage struct {
 	After string `json:"after"`
 }
 
-// Property represents a contact property
+// Property represents an object property definition
 type Property struct {
-	Name        string `json:"name"`
-	Label       string `json:"label"`
-	Type        string `json:"type"`
-	FieldType   string `json:"fieldType"`
-	Description string `json:"description"`
-	Options     []struct {
-		Label string `json:"label"`
-		Value string `json:"value"`
-	} `json:"options,omitempty"`
+	Name        string           `json:"name"`
+	Label       string           `json:"label"`
+	Type        string           `json:"type"`
+	FieldType   string           `json:"fieldType"`
+	Description string           `json:"description"`
+	Options     []PropertyOption `json:"options,omitempty"`
+	// ModificationMetadata is set by HubSpot for every property
+	ModificationMetadata *PropertyModificationMetadata `json:"modificationMetadata,omitempty"`
+}
+
+// PropertyModificationMetadata describes whether a property's value can be changed
+type PropertyModificationMetadata struct {
+	ReadOnlyValue      bool `json:"readOnlyValue"`
+	ReadOnlyDefinition bool `json:"readOnlyDefinition"`
+	Archivable         bool `json:"archivable"`
+}
+
+// ReadOnly reports whether HubSpot rejects writes to the property's value
+func (p Property) ReadOnly() bool {
+	return p.ModificationMetadata != nil && p.ModificationMetadata.ReadOnlyValue
+}
+
+// PropertyOption represents an allowed value of an enumeration property
+type PropertyOption struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
 }
 
 // PropertiesResponse represents the response for properties
@@ -75,215 +131,146 @@ type PropertiesResponse struct {
 	Results []Property `json:"results"`
 }
 
-// doRequest performs an HTTP request to the HubSpot API
-func (c *Client) doRequest(method, endpoint string, body interface{}) ([]byte, error) {
-	var reqBody io.Reader
+// doRequest performs an HTTP request to the HubSpot API, retrying throttled
+// and transient failures according to the client's retry policy
+func (c *Client) doRequest(ctx context.Context, method, endpoint string, body interface{}) ([]byte, error) {
+	var jsonData []byte
 	if body != nil {
-		jsonData, err := json.Marshal(body)
+		var err error
+		jsonData, err = json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal request body: %w", err)
 		}
-		reqBody = bytes.NewBuffer(jsonData)
 	}
 
-	req, err := http.NewRequest(method, c.baseURL+endpoint, reqBody)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
+	safe := retrySafe(method, endpoint)
+	reauthorized := false
+	for attempt := 0; ; attempt++ {
+		if wait := c.limiter.delay(time.Now()); wait > 0 && !c.replay {
+			if err := c.sleep(ctx, wait); err != nil {
+				return nil, err
+			}
+		}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+		var reqBody io.Reader
+		if jsonData != nil {
+			reqBody = bytes.NewReader(jsonData)
+		}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to execute request: %w", err)
-	}
-	defer resp.Body.Close()
+		req, err := http.NewRequestWithContext(ctx, method, c.baseURL+endpoint, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create request: %w", err)
+		}
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %w", err)
-	}
+		token := c.apiKey
+		if c.tokens != nil {
+			token, err = c.tokens.Token(ctx)
+			if err != nil {
+				return nil, err
+			}
+		}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(respBody))
-	}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
 
-	return respBody, nil
-}
+		resp, err := c.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if safe && attempt < c.retry.MaxRetries && !c.replay {
+				if err := c.sleep(ctx, c.retry.backoff(attempt)); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, fmt.Errorf("failed to execute request: %w", err)
+		}
 
-// ListContacts retrieves all contacts with pagination
-func (c *Client) ListContacts(limit int, after string) (*ContactResponse, error) {
-	endpoint := "/crm/v3/objects/contacts"
-	params := url.Values{}
-	params.Add("limit", fmt.Sprintf("%d", limit))
-	if after != "" {
-		params.Add("after", after)
-	}
-	params.Add("properties", "email,firstname,lastname,company,hs_lead_status,lifecyclestage")
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
 
-	if len(params) > 0 {
-		endpoint += "?" + params.Encode()
-	}
+		c.limiter.observe(resp.Header, time.Now())
 
-	respBody, err := c.doRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
+		if resp.StatusCode == http.StatusUnauthorized && !reauthorized {
+			// The access token may have been revoked or expired early
+			if ts, ok := c.tokens.(interface{ Invalidate() }); ok {
+				ts.Invalidate()
+				reauthorized = true
+				attempt--
+				continue
+			}
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			retry := retryableStatus(resp.StatusCode) &&
+				(safe || resp.StatusCode == http.StatusTooManyRequests)
+			if retry && attempt < c.retry.MaxRetries {
+				wait, ok := retryAfter(resp.Header, time.Now())
+				switch {
+				case !ok:
+					wait = c.retry.backoff(attempt)
+				case c.retry.MaxDelay > 0 && wait > c.retry.MaxDelay:
+					// Waiting less than asked would only be throttled again,
+					// and waiting longer than the policy allows could block
+					// for hours
+					return nil, newAPIError(resp.StatusCode, respBody)
+				}
+				if c.replay {
+					continue
+				}
+				if err := c.sleep(ctx, wait); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			return nil, newAPIError(resp.StatusCode, respBody)
+		}
 
-	var contactResp ContactResponse
-	if err := json.Unmarshal(respBody, &contactResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		return respBody, nil
 	}
+}
 
-	return &contactResp, nil
+// ListContacts retrieves all contacts with pagination
+func (c *Client) ListContacts(ctx context.Context, limit int, after string) (*ContactResponse, error) {
+	return c.ListObjects(ctx, Contacts.Name, limit, after, Contacts.DefaultProperties)
 }
 
 // GetContact retrieves a specific contact by ID
-func (c *Client) GetContact(contactID string) (*Contact, error) {
-	endpoint := fmt.Sprintf("/crm/v3/objects/contacts/%s", contactID)
-	params := url.Values{}
-	params.Add("properties", "email,firstname,lastname,company,hs_lead_status,lifecyclestage")
-
-	endpoint += "?" + params.Encode()
-
-	respBody, err := c.doRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var contact Contact
-	if err := json.Unmarshal(respBody, &contact); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &contact, nil
+func (c *Client) GetContact(ctx context.Context, contactID string) (*Contact, error) {
+	return c.GetObject(ctx, Contacts.Name, contactID, Contacts.DefaultProperties)
 }
 
 // CreateContact creates a new contact
-func (c *Client) CreateContact(properties map[string]interface{}) (*Contact, error) {
-	endpoint := "/crm/v3/objects/contacts"
-
-	requestBody := map[string]interface{}{
-		"properties": properties,
-	}
-
-	respBody, err := c.doRequest("POST", endpoint, requestBody)
-	if err != nil {
-		return nil, err
-	}
-
-	var contact Contact
-	if err := json.Unmarshal(respBody, &contact); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &contact, nil
+func (c *Client) CreateContact(ctx context.Context, properties map[string]interface{}) (*Contact, error) {
+	return c.CreateObject(ctx, Contacts.Name, properties)
 }
 
 // UpdateContact updates an existing contact
-func (c *Client) UpdateContact(contactID string, properties map[string]interface{}) (*Contact, error) {
-	endpoint := fmt.Sprintf("/crm/v3/objects/contacts/%s", contactID)
-
-	requestBody := map[string]interface{}{
-		"properties": properties,
-	}
-
-	respBody, err := c.doRequest("PATCH", endpoint, requestBody)
-	if err != nil {
-		return nil, err
-	}
-
-	var contact Contact
-	if err := json.Unmarshal(respBody, &contact); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &contact, nil
+func (c *Client) UpdateContact(ctx context.Context, contactID string, properties map[string]interface{}) (*Contact, error) {
+	return c.UpdateObject(ctx, Contacts.Name, contactID, properties)
 }
 
 // DeleteContact deletes a contact by ID
-func (c *Client) DeleteContact(contactID string) error {
-	endpoint := fmt.Sprintf("/crm/v3/objects/contacts/%s", contactID)
-	_, err := c.doRequest("DELETE", endpoint, nil)
-	return err
+func (c *Client) DeleteContact(ctx context.Context, contactID string) error {
+	return c.DeleteObject(ctx, Contacts.Name, contactID)
 }
 
 // SearchContacts searches for contacts using HubSpot's search API
 // The query parameter can be a property name and value in format "property=value"
-// or just a value to search in email, firstname, and lastname fields
-func (c *Client) SearchContacts(query string, limit int) (*ContactResponse, error) {
-	endpoint := "/crm/v3/objects/contacts/search"
-
-	// Parse query - if it contains "=", treat as property=value, otherwise search in common fields
-	var filterGroups []map[string]interface{}
-
-	if strings.Contains(query, "=") {
-		// Property-based search
-		parts := strings.SplitN(query, "=", 2)
-		if len(parts) == 2 {
-			property := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
-			filterGroups = []map[string]interface{}{
-				{
-					"filters": []map[string]interface{}{
-						{
-							"propertyName": property,
-							"operator":     "EQ",
-							"value":        value,
-						},
-					},
-				},
-			}
-		}
-	} else {
-		// Search in email field (most common use case)
-		value := strings.TrimSpace(query)
-		filterGroups = []map[string]interface{}{
-			{
-				"filters": []map[string]interface{}{
-					{
-						"propertyName": "email",
-						"operator":     "CONTAINS_TOKEN",
-						"value":        value,
-					},
-				},
-			},
-		}
-	}
-
-	requestBody := map[string]interface{}{
-		"filterGroups": filterGroups,
-		"limit":        limit,
-		"properties":   []string{"email", "firstname", "lastname", "company", "hs_lead_status", "lifecyclestage"},
-	}
-
-	respBody, err := c.doRequest("POST", endpoint, requestBody)
-	if err != nil {
-		return nil, err
-	}
-
-	var contactResp ContactResponse
-	if err := json.Unmarshal(respBody, &contactResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return &contactResp, nil
+// or just a value to search in the email field
+func (c *Client) SearchContacts(ctx context.Context, query string, limit int) (*ContactResponse, error) {
+	return c.SearchObjects(ctx, Contacts.Name, SearchRequest{
+		FilterGroups: SimpleQuery(query, Contacts.SearchProperty),
+		Limit:        limit,
+		Properties:   Contacts.DefaultProperties,
+	})
 }
 
 // ListProperties retrieves all contact properties
-func (c *Client) ListProperties() ([]Property, error) {
-	endpoint := "/crm/v3/properties/contacts"
-
-	respBody, err := c.doRequest("GET", endpoint, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	var propsResp PropertiesResponse
-	if err := json.Unmarshal(respBody, &propsResp); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-	}
-
-	return propsResp.Results, nil
+func (c *Client) ListProperties(ctx context.Context) ([]Property, error) {
+	return c.ListObjectProperties(ctx, Contacts.Name)
 }
@@ -1,6 +1,7 @@
 package hubspot
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -27,6 +28,33 @@ func TestNewClient(t *testing.T) {
 	}
 }
 
+func TestNewClient_WithBaseURL(t *testing.T) {
+	client := NewClient("test-api-key", WithBaseURL("https://sandbox.example.com/"))
+
+	if client.baseURL != "https://sandbox.example.com" {
+		t.Errorf("Expected base URL without trailing slash, got %s", client.baseURL)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestNewClient_WithTransport(t *testing.T) {
+	var seen string
+	client := NewClient("test-api-key", WithTransport(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		seen = r.URL.Path
+		return &http.Response{StatusCode: http.StatusOK, Header: make(http.Header), Body: http.NoBody}, nil
+	})))
+
+	if _, err := client.doRequest(context.Background(), "GET", "/test", nil); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+	if seen != "/test" {
+		t.Errorf("Expected the request to go through the transport, got %q", seen)
+	}
+}
+
 func TestClient_doRequest(t *testing.T) {
 	// Create a test server
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -41,7 +69,7 @@ func TestClient_doRequest(t *testing.T) {
 	client := NewClient("test-api-key")
 	client.baseURL = server.URL
 
-	_, err := client.doRequest("GET", "/test", nil)
+	_, err := client.doRequest(context.Background(), "GET", "/test", nil)
 	if err != nil {
 		t.Errorf("doRequest failed: %v", err)
 	}
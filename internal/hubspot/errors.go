@@ -0,0 +1,106 @@
+package hubspot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HubSpot error categories returned in the error envelope
+const (
+	CategoryValidation   = "VALIDATION_ERROR"
+	CategoryNotFound     = "OBJECT_NOT_FOUND"
+	CategoryMissingScope = "MISSING_SCOPES"
+	CategoryRateLimit    = "RATE_LIMITS"
+	CategoryConflict     = "CONFLICT"
+	CategoryUnauthorized = "INVALID_AUTHENTICATION"
+)
+
+// ErrorDetail represents a single entry of the errors[] array in a HubSpot error
+type ErrorDetail struct {
+	Message     string              `json:"message"`
+	Code        string              `json:"code,omitempty"`
+	In          string              `json:"in,omitempty"`
+	SubCategory string              `json:"subCategory,omitempty"`
+	Context     map[string][]string `json:"context,omitempty"`
+}
+
+// APIError represents an error response returned by the HubSpot API
+type APIError struct {
+	StatusCode    int                 `json:"-"`
+	Status        string              `json:"status"`
+	Message       string              `json:"message"`
+	CorrelationID string              `json:"correlationId"`
+	Category      string              `json:"category"`
+	SubCategory   string              `json:"subCategory,omitempty"`
+	Errors        []ErrorDetail       `json:"errors,omitempty"`
+	Context       map[string][]string `json:"context,omitempty"`
+	// Body holds the raw response body, which is useful when it is not a HubSpot envelope
+	Body string `json:"-"`
+}
+
+// newAPIError builds an APIError from a non-2xx response
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{}
+	if err := json.Unmarshal(body, apiErr); err != nil {
+		apiErr = &APIError{}
+	}
+	apiErr.StatusCode = statusCode
+	apiErr.Body = string(body)
+	if apiErr.Message == "" {
+		apiErr.Message = strings.TrimSpace(string(body))
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = http.StatusText(statusCode)
+	}
+	return apiErr
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	if e.Category != "" {
+		return fmt.Sprintf("API error (status %d, %s): %s", e.StatusCode, e.Category, e.Message)
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether the error means the requested object does not exist
+func (e *APIError) IsNotFound() bool {
+	return e.StatusCode == http.StatusNotFound || e.Category == CategoryNotFound
+}
+
+// IsValidation reports whether the request was rejected as invalid, such as an unknown property
+func (e *APIError) IsValidation() bool {
+	return e.Category == CategoryValidation || e.StatusCode == http.StatusBadRequest
+}
+
+// IsMissingScope reports whether the token lacks a scope required by the request
+func (e *APIError) IsMissingScope() bool {
+	return e.Category == CategoryMissingScope || e.StatusCode == http.StatusForbidden
+}
+
+// IsUnauthorized reports whether the token was rejected
+func (e *APIError) IsUnauthorized() bool {
+	return e.StatusCode == http.StatusUnauthorized || e.Category == CategoryUnauthorized
+}
+
+// IsRateLimited reports whether the request was throttled
+func (e *APIError) IsRateLimited() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.Category == CategoryRateLimit
+}
+
+// IsConflict reports whether the request conflicts with an existing object, such as a duplicate email
+func (e *APIError) IsConflict() bool {
+	return e.StatusCode == http.StatusConflict || e.Category == CategoryConflict
+}
+
+// AsAPIError returns the APIError wrapped in err, if any
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,55 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_doRequestReturnsAPIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{
+			"status": "error",
+			"message": "Property values were not valid",
+			"correlationId": "abc-123",
+			"category": "VALIDATION_ERROR",
+			"errors": [{
+				"message": "Property \"foo\" does not exist",
+				"code": "PROPERTY_DOESNT_EXIST",
+				"context": {"propertyName": ["foo"]}
+			}]
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	_, err := client.doRequest(context.Background(), "PATCH", "/crm/v3/objects/contacts/1", nil)
+	apiErr, ok := AsAPIError(fmt.Errorf("wrapped: %w", err))
+	if !ok {
+		t.Fatalf("Expected APIError, got %T: %v", err, err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.CorrelationID != "abc-123" {
+		t.Errorf("Unexpected status or correlation ID: %+v", apiErr)
+	}
+	if !apiErr.IsValidation() || apiErr.IsNotFound() {
+		t.Errorf("Expected a validation error, got category %q", apiErr.Category)
+	}
+	if len(apiErr.Errors) != 1 || apiErr.Errors[0].Context["propertyName"][0] != "foo" {
+		t.Errorf("Expected per-field error for foo, got %+v", apiErr.Errors)
+	}
+}
+
+func TestNewAPIError_nonJSONBody(t *testing.T) {
+	apiErr := newAPIError(http.StatusNotFound, []byte("not found"))
+	if apiErr.Message != "not found" || !apiErr.IsNotFound() {
+		t.Errorf("Unexpected error: %+v", apiErr)
+	}
+	if apiErr.Error() != "API error (status 404): not found" {
+		t.Errorf("Unexpected message: %s", apiErr.Error())
+	}
+}
@@ -0,0 +1,71 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// PropertyVersion represents one historical value of a property
+type PropertyVersion struct {
+	Value           string `json:"value"`
+	Timestamp       string `json:"timestamp"`
+	SourceType      string `json:"sourceType"`
+	SourceID        string `json:"sourceId,omitempty"`
+	SourceLabel     string `json:"sourceLabel,omitempty"`
+	UpdatedByUserID int64  `json:"updatedByUserId,omitempty"`
+}
+
+// PropertyChange is a PropertyVersion tagged with its property name, used to
+// build a timeline across properties
+type PropertyChange struct {
+	Property string `json:"property"`
+	PropertyVersion
+}
+
+// GetObjectHistory retrieves an object together with the value history of the given properties
+func (c *Client) GetObjectHistory(ctx context.Context, objectType, objectID string, properties []string) (*Object, error) {
+	params := url.Values{}
+	params.Add("propertiesWithHistory", strings.Join(properties, ","))
+	endpoint := objectsEndpoint(objectType, objectID) + "?" + params.Encode()
+
+	respBody, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var object Object
+	if err := json.Unmarshal(respBody, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &object, nil
+}
+
+// Timeline flattens an object's property history into changes ordered from
+// oldest to newest
+func (o Object) Timeline() []PropertyChange {
+	var changes []PropertyChange
+	for property, versions := range o.PropertiesWithHistory {
+		for _, version := range versions {
+			changes = append(changes, PropertyChange{Property: property, PropertyVersion: version})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		ti, erri := time.Parse(time.RFC3339Nano, changes[i].Timestamp)
+		tj, errj := time.Parse(time.RFC3339Nano, changes[j].Timestamp)
+		if erri == nil && errj == nil && !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		if changes[i].Timestamp != changes[j].Timestamp {
+			return changes[i].Timestamp < changes[j].Timestamp
+		}
+		return changes[i].Property < changes[j].Property
+	})
+	return changes
+}
@@ -0,0 +1,52 @@
+package hubspot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_GetObjectHistory(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("propertiesWithHistory"); got != "email,lifecyclestage" {
+			t.Errorf("Unexpected propertiesWithHistory %q", got)
+		}
+		w.Write([]byte(`{
+			"id": "1",
+			"properties": {},
+			"propertiesWithHistory": {
+				"email": [
+					{"value": "new@example.com", "timestamp": "2025-03-01T10:00:00.000Z", "sourceType": "CRM_UI", "sourceId": "userId:42"},
+					{"value": "old@example.com", "timestamp": "2024-01-05T09:30:00.000Z", "sourceType": "FORM"}
+				],
+				"lifecyclestage": [
+					{"value": "lead", "timestamp": "2024-06-01T00:00:00Z", "sourceType": "INTEGRATION", "sourceId": "12345"}
+				]
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	object, err := client.GetObjectHistory(context.Background(), Contacts.Name, "1", []string{"email", "lifecyclestage"})
+	if err != nil {
+		t.Fatalf("GetObjectHistory failed: %v", err)
+	}
+
+	timeline := object.Timeline()
+	if len(timeline) != 3 {
+		t.Fatalf("Expected 3 changes, got %d", len(timeline))
+	}
+	want := []string{"old@example.com", "lead", "new@example.com"}
+	for i, change := range timeline {
+		if change.Value != want[i] {
+			t.Errorf("Change %d: expected %s, got %s (%s)", i, want[i], change.Value, change.Timestamp)
+		}
+	}
+	if timeline[2].Property != "email" || timeline[2].SourceID != "userId:42" {
+		t.Errorf("Unexpected latest change: %+v", timeline[2])
+	}
+}
@@ -0,0 +1,151 @@
+package hubspot
+
+import (
+	"context"
+	"iter"
+)
+
+// pageFunc fetches the page of results starting at the after cursor
+type pageFunc func(ctx context.Context, after string) (*ObjectResponse, error)
+
+// Iterator walks the results of a list or search request page by page,
+// fetching the next page only when the current one is exhausted.
+//
+//	it := client.ListIterator(ctx, hubspot.Contacts.Name, 100, nil)
+//	for it.Next() {
+//		contact := it.Object()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type Iterator struct {
+	ctx     context.Context
+	fetch   pageFunc
+	page    []Object
+	index   int
+	current Object
+	after   string
+	started bool
+	done    bool
+	err     error
+	total   int
+}
+
+func newIterator(ctx context.Context, after string, fetch pageFunc) *Iterator {
+	return &Iterator{ctx: ctx, fetch: fetch, after: after}
+}
+
+// ListIterator returns an iterator over all objects of a type, requesting
+// pageSize objects per page
+func (c *Client) ListIterator(ctx context.Context, objectType string, pageSize int, properties []string) *Iterator {
+	return c.ListIteratorFrom(ctx, objectType, pageSize, properties, "")
+}
+
+// ListIteratorFrom is like ListIterator but starts at a previously saved
+// after cursor, as returned by Iterator.Cursor
+func (c *Client) ListIteratorFrom(ctx context.Context, objectType string, pageSize int, properties []string, after string) *Iterator {
+	return newIterator(ctx, after, func(ctx context.Context, after string) (*ObjectResponse, error) {
+		return c.ListObjects(ctx, objectType, pageSize, after, properties)
+	})
+}
+
+// ArchivedIterator returns an iterator over the archived objects of a type
+func (c *Client) ArchivedIterator(ctx context.Context, objectType string, pageSize int, properties []string) *Iterator {
+	return newIterator(ctx, "", func(ctx context.Context, after string) (*ObjectResponse, error) {
+		return c.ListArchivedObjects(ctx, objectType, pageSize, after, properties)
+	})
+}
+
+// SearchIterator returns an iterator over all results of a search. The
+// request's Limit is used as the page size. HubSpot stops paging searches
+// after 10,000 results.
+func (c *Client) SearchIterator(ctx context.Context, objectType string, search SearchRequest) *Iterator {
+	return newIterator(ctx, search.After, func(ctx context.Context, after string) (*ObjectResponse, error) {
+		search.After = after
+		return c.SearchObjects(ctx, objectType, search)
+	})
+}
+
+// Next advances to the next object, fetching a new page when needed. It
+// returns false when there are no more objects or an error occurred.
+func (it *Iterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for it.index >= len(it.page) {
+		if it.done {
+			return false
+		}
+		if err := it.ctx.Err(); err != nil {
+			it.err = err
+			return false
+		}
+
+		resp, err := it.fetch(it.ctx, it.after)
+		if err != nil {
+			it.err = err
+			return false
+		}
+
+		it.started = true
+		it.page = resp.Results
+		it.index = 0
+		if resp.Total > 0 {
+			it.total = resp.Total
+		}
+		if resp.Paging != nil && resp.Paging.Next != nil && resp.Paging.Next.After != "" {
+			it.after = resp.Paging.Next.After
+		} else {
+			it.after = ""
+			it.done = true
+		}
+	}
+
+	it.current = it.page[it.index]
+	it.index++
+	return true
+}
+
+// Object returns the current object
+func (it *Iterator) Object() Object {
+	return it.current
+}
+
+// Err returns the error that stopped the iteration, if any
+func (it *Iterator) Err() error {
+	return it.err
+}
+
+// Cursor returns the after token of the page following the current one, or
+// an empty string once the last page has been fetched. Objects of the
+// current page that have not been returned yet are not covered by it.
+func (it *Iterator) Cursor() string {
+	return it.after
+}
+
+// PageDone reports whether every object of the fetched page has been returned
+func (it *Iterator) PageDone() bool {
+	return it.started && it.index >= len(it.page)
+}
+
+// Total returns the total number of matches reported by a search, or 0 if unknown
+func (it *Iterator) Total() int {
+	return it.total
+}
+
+// All returns the remaining objects as a Go 1.23 range-over-func sequence.
+// Iteration stops after yielding a non-nil error.
+func (it *Iterator) All() iter.Seq2[Object, error] {
+	return func(yield func(Object, error) bool) {
+		for it.Next() {
+			if !yield(it.Object(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(Object{}, err)
+		}
+	}
+}
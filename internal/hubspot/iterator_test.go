@@ -0,0 +1,105 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// pagedServer serves three pages of two contacts for both list and search requests
+func pagedServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		after := r.URL.Query().Get("after")
+		if r.Method == "POST" {
+			var search SearchRequest
+			json.NewDecoder(r.Body).Decode(&search)
+			after = search.After
+		}
+
+		page := 0
+		fmt.Sscan(after, &page)
+		resp := ObjectResponse{Total: 6}
+		for i := 0; i < 2; i++ {
+			resp.Results = append(resp.Results, Object{ID: fmt.Sprint(page*2 + i)})
+		}
+		if page < 2 {
+			resp.Paging = &Paging{Next: &NextPage{After: fmt.Sprint(page + 1)}}
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+}
+
+func TestIterator_List(t *testing.T) {
+	server := pagedServer(t)
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	it := client.ListIterator(context.Background(), Contacts.Name, 2, nil)
+	var ids []string
+	for it.Next() {
+		ids = append(ids, it.Object().ID)
+		if len(ids) == 2 && (!it.PageDone() || it.Cursor() != "1") {
+			t.Errorf("Expected first page done with cursor 1, got %v %q", it.PageDone(), it.Cursor())
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("Iteration failed: %v", err)
+	}
+	if fmt.Sprint(ids) != "[0 1 2 3 4 5]" {
+		t.Errorf("Unexpected IDs %v", ids)
+	}
+	if it.Cursor() != "" {
+		t.Errorf("Expected empty cursor after the last page, got %q", it.Cursor())
+	}
+}
+
+func TestIterator_SearchAll(t *testing.T) {
+	server := pagedServer(t)
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	it := client.SearchIterator(context.Background(), Contacts.Name, SearchRequest{Limit: 2})
+	count := 0
+	for object, err := range it.All() {
+		if err != nil {
+			t.Fatalf("Iteration failed: %v", err)
+		}
+		if object.ID != fmt.Sprint(count) {
+			t.Errorf("Expected ID %d, got %s", count, object.ID)
+		}
+		count++
+		if count == 3 {
+			break
+		}
+	}
+	if count != 3 || it.Total() != 6 {
+		t.Errorf("Expected to stop after 3 of 6 results, got %d of %d", count, it.Total())
+	}
+}
+
+func TestIterator_Cancelled(t *testing.T) {
+	server := pagedServer(t)
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	it := client.ListIterator(ctx, Contacts.Name, 2, nil)
+	count := 0
+	for it.Next() {
+		count++
+		cancel()
+	}
+	if count != 2 || it.Err() != context.Canceled {
+		t.Errorf("Expected to stop after the first page with context.Canceled, got %d, %v", count, it.Err())
+	}
+}
@@ -0,0 +1,309 @@
+package hubspot
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HubSpot OAuth endpoints
+const (
+	AuthURL  = "https://app.hubspot.com/oauth/authorize"
+	TokenURL = "https://api.hubapi.com/oauth/v1/token"
+)
+
+// tokenExpiryDelta refreshes access tokens slightly before they expire
+const tokenExpiryDelta = time.Minute
+
+// defaultTokenLifetime is assumed for access tokens issued without
+// expires_in, HubSpot's usual lifetime
+const defaultTokenLifetime = 30 * time.Minute
+
+// TokenSource supplies the bearer token sent with every request
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// WithTokenSource authenticates requests with tokens from ts instead of a static API key
+func WithTokenSource(ts TokenSource) Option {
+	return func(c *Client) {
+		c.tokens = ts
+	}
+}
+
+// OAuthConfig describes a HubSpot public app
+type OAuthConfig struct {
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+	// AuthURL and TokenURL default to HubSpot's endpoints when empty
+	AuthURL  string
+	TokenURL string
+	// HTTPClient is used for token requests; http.DefaultClient when nil
+	HTTPClient *http.Client
+}
+
+// Token represents an OAuth token pair
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	ExpiresIn    int       `json:"expires_in,omitempty"`
+	Expiry       time.Time `json:"expiry"`
+}
+
+// Valid reports whether the access token can still be used
+func (t *Token) Valid() bool {
+	return t != nil && t.AccessToken != "" && time.Now().Add(tokenExpiryDelta).Before(t.Expiry)
+}
+
+// oauthError represents an error response from the token endpoint
+type oauthError struct {
+	Status        string `json:"status"`
+	Message       string `json:"message"`
+	CorrelationID string `json:"correlationId"`
+}
+
+// AuthCodeURL returns the URL the user visits to authorize the app
+func (cfg OAuthConfig) AuthCodeURL(state string) string {
+	authURL := cfg.AuthURL
+	if authURL == "" {
+		authURL = AuthURL
+	}
+
+	params := url.Values{}
+	params.Set("client_id", cfg.ClientID)
+	params.Set("redirect_uri", cfg.RedirectURL)
+	params.Set("scope", strings.Join(cfg.Scopes, " "))
+	params.Set("state", state)
+	return authURL + "?" + params.Encode()
+}
+
+// Exchange trades an authorization code for a token pair
+func (cfg OAuthConfig) Exchange(ctx context.Context, code string) (*Token, error) {
+	params := url.Values{}
+	params.Set("grant_type", "authorization_code")
+	params.Set("client_id", cfg.ClientID)
+	params.Set("client_secret", cfg.ClientSecret)
+	params.Set("redirect_uri", cfg.RedirectURL)
+	params.Set("code", code)
+	return cfg.requestToken(ctx, params)
+}
+
+// Refresh obtains a new access token using a refresh token
+func (cfg OAuthConfig) Refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	params := url.Values{}
+	params.Set("grant_type", "refresh_token")
+	params.Set("client_id", cfg.ClientID)
+	params.Set("client_secret", cfg.ClientSecret)
+	params.Set("redirect_uri", cfg.RedirectURL)
+	params.Set("refresh_token", refreshToken)
+
+	token, err := cfg.requestToken(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	if token.RefreshToken == "" {
+		token.RefreshToken = refreshToken
+	}
+	return token, nil
+}
+
+func (cfg OAuthConfig) requestToken(ctx context.Context, params url.Values) (*Token, error) {
+	tokenURL := cfg.TokenURL
+	if tokenURL == "" {
+		tokenURL = TokenURL
+	}
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(params.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute token request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token response: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var oauthErr oauthError
+		if json.Unmarshal(respBody, &oauthErr) == nil && oauthErr.Message != "" {
+			return nil, fmt.Errorf("token request failed (status %d): %s", resp.StatusCode, oauthErr.Message)
+		}
+		return nil, fmt.Errorf("token request failed (status %d): %s", resp.StatusCode, string(respBody))
+	}
+
+	var token Token
+	if err := json.Unmarshal(respBody, &token); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return nil, errors.New("token response did not include an access token")
+	}
+	lifetime := time.Duration(token.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	token.Expiry = time.Now().Add(lifetime)
+	return &token, nil
+}
+
+// OAuthTokenSource hands out access tokens and transparently refreshes them
+// when they expire or are rejected
+type OAuthTokenSource struct {
+	config OAuthConfig
+	mu     sync.Mutex
+	token  *Token
+	// onRefresh is called with every refreshed token, e.g. to persist it
+	onRefresh func(*Token) error
+}
+
+// NewOAuthTokenSource creates a token source starting from a saved token.
+// onRefresh may be nil.
+func NewOAuthTokenSource(config OAuthConfig, token *Token, onRefresh func(*Token) error) *OAuthTokenSource {
+	return &OAuthTokenSource{config: config, token: token, onRefresh: onRefresh}
+}
+
+// Token returns a valid access token, refreshing it first if needed
+func (s *OAuthTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token.Valid() {
+		return s.token.AccessToken, nil
+	}
+	if s.token == nil || s.token.RefreshToken == "" {
+		return "", errors.New("no refresh token available; run hscli auth login")
+	}
+
+	token, err := s.config.Refresh(ctx, s.token.RefreshToken)
+	if err != nil {
+		return "", fmt.Errorf("failed to refresh access token: %w", err)
+	}
+	s.token = token
+
+	if s.onRefresh != nil {
+		if err := s.onRefresh(token); err != nil {
+			return "", fmt.Errorf("failed to save refreshed token: %w", err)
+		}
+	}
+	return token.AccessToken, nil
+}
+
+// Invalidate forces the next Token call to refresh the access token
+func (s *OAuthTokenSource) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != nil {
+		s.token.Expiry = time.Time{}
+	}
+}
+
+// LoginLoopback runs the authorization code flow with a loopback redirect.
+// It listens on addr (e.g. "localhost:8085"), points the config's redirect
+// URL at it, calls open with the authorization URL, waits for HubSpot to
+// redirect back with a code and exchanges it for a token pair. It returns
+// the token and the redirect URL it used, which refreshes must send again.
+func LoginLoopback(ctx context.Context, config OAuthConfig, addr string, open func(authURL string) error) (*Token, string, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to start callback server: %w", err)
+	}
+	defer listener.Close()
+
+	// Keep the hostname the app was registered with, but use the actual port
+	// in case addr asked for any free one
+	host, _, _ := net.SplitHostPort(addr)
+	_, port, _ := net.SplitHostPort(listener.Addr().String())
+	config.RedirectURL = "http://" + net.JoinHostPort(host, port) + "/oauth-callback"
+
+	state, err := randomState()
+	if err != nil {
+		return nil, "", err
+	}
+
+	type result struct {
+		code string
+		err  error
+	}
+	results := make(chan result, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth-callback", func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		var res result
+		switch {
+		case query.Get("state") != state:
+			res.err = errors.New("authorization callback state mismatch")
+		case query.Get("error") != "":
+			res.err = fmt.Errorf("authorization denied: %s %s", query.Get("error"), query.Get("error_description"))
+		case query.Get("code") == "":
+			res.err = errors.New("authorization callback did not include a code")
+		default:
+			res.code = query.Get("code")
+		}
+
+		if res.err != nil {
+			http.Error(w, res.err.Error(), http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "hscli is now authorized. You can close this window.")
+		}
+		select {
+		case results <- res:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	if err := open(config.AuthCodeURL(state)); err != nil {
+		return nil, "", err
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, "", ctx.Err()
+	case res := <-results:
+		if res.err != nil {
+			return nil, "", res.err
+		}
+		token, err := config.Exchange(ctx, res.code)
+		if err != nil {
+			return nil, "", err
+		}
+		return token, config.RedirectURL, nil
+	}
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate state: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
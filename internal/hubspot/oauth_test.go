@@ -0,0 +1,283 @@
+package hubspot
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// newTokenServer fakes HubSpot's token endpoint, issuing numbered access tokens
+func newTokenServer(t *testing.T, requests *[]url.Values) *httptest.Server {
+	t.Helper()
+	issued := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			t.Errorf("Expected POST, got %s", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("Failed to parse form: %v", err)
+		}
+		*requests = append(*requests, r.PostForm)
+
+		if r.PostForm.Get("code") == "bad" {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"status":"BAD_AUTH_CODE","message":"auth code not found"}`))
+			return
+		}
+
+		issued++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"access-%d","refresh_token":"refresh-token","expires_in":1800,"token_type":"bearer"}`, issued)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestOAuthConfig_AuthCodeURL(t *testing.T) {
+	cfg := OAuthConfig{
+		ClientID:    "client",
+		RedirectURL: "http://localhost:8085/oauth-callback",
+		Scopes:      []string{"oauth", "crm.objects.contacts.read"},
+	}
+
+	u, err := url.Parse(cfg.AuthCodeURL("xyz"))
+	if err != nil {
+		t.Fatalf("Failed to parse URL: %v", err)
+	}
+	if u.Scheme+"://"+u.Host+u.Path != AuthURL {
+		t.Errorf("Expected %s, got %s", AuthURL, u.String())
+	}
+
+	query := u.Query()
+	if query.Get("client_id") != "client" || query.Get("state") != "xyz" {
+		t.Errorf("Unexpected query: %v", query)
+	}
+	if query.Get("scope") != "oauth crm.objects.contacts.read" {
+		t.Errorf("Expected space-separated scopes, got %q", query.Get("scope"))
+	}
+	if query.Get("redirect_uri") != cfg.RedirectURL {
+		t.Errorf("Expected redirect URI %s, got %s", cfg.RedirectURL, query.Get("redirect_uri"))
+	}
+}
+
+func TestOAuthConfig_ExchangeError(t *testing.T) {
+	var requests []url.Values
+	server := newTokenServer(t, &requests)
+
+	cfg := OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: server.URL}
+	_, err := cfg.Exchange(context.Background(), "bad")
+	if err == nil {
+		t.Fatal("Expected error for a rejected code")
+	}
+	if got := err.Error(); got != "token request failed (status 400): auth code not found" {
+		t.Errorf("Unexpected error: %s", got)
+	}
+}
+
+func TestOAuthConfig_ExchangeWithoutExpiresIn(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"access_token":"access","refresh_token":"refresh-token","token_type":"bearer"}`))
+	}))
+	defer server.Close()
+
+	cfg := OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: server.URL}
+	token, err := cfg.Exchange(context.Background(), "auth-code")
+	if err != nil {
+		t.Fatalf("Exchange failed: %v", err)
+	}
+	if !token.Valid() {
+		t.Error("Expected a token without expires_in to be valid")
+	}
+	if lifetime := time.Until(token.Expiry); lifetime > defaultTokenLifetime || lifetime < defaultTokenLifetime-time.Minute {
+		t.Errorf("Expected the default lifetime of %s, got %s", defaultTokenLifetime, lifetime)
+	}
+}
+
+func TestLoginLoopback(t *testing.T) {
+	var requests []url.Values
+	server := newTokenServer(t, &requests)
+
+	cfg := OAuthConfig{
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"oauth"},
+		TokenURL:     server.URL,
+	}
+
+	// Play the browser: follow the redirect HubSpot would make after consent
+	open := func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		query := u.Query()
+		callback := query.Get("redirect_uri") + "?code=auth-code&state=" + url.QueryEscape(query.Get("state"))
+		go func() {
+			resp, err := http.Get(callback)
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	token, redirectURL, err := LoginLoopback(ctx, cfg, "localhost:0", open)
+	if err != nil {
+		t.Fatalf("LoginLoopback failed: %v", err)
+	}
+	if token.AccessToken != "access-1" || token.RefreshToken != "refresh-token" {
+		t.Errorf("Unexpected token: %+v", token)
+	}
+	if !token.Valid() {
+		t.Error("Expected a freshly issued token to be valid")
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 token request, got %d", len(requests))
+	}
+	form := requests[0]
+	if form.Get("grant_type") != "authorization_code" || form.Get("code") != "auth-code" {
+		t.Errorf("Unexpected token request: %v", form)
+	}
+	if redirect := form.Get("redirect_uri"); redirect == "" || redirect == "http://localhost:0/oauth-callback" {
+		t.Errorf("Expected the redirect URI to use the listening port, got %q", redirect)
+	}
+	if redirectURL != form.Get("redirect_uri") {
+		t.Errorf("Expected the returned redirect URL %q to be the one exchanged, got %q", form.Get("redirect_uri"), redirectURL)
+	}
+}
+
+func TestLoginLoopback_StateMismatch(t *testing.T) {
+	var requests []url.Values
+	server := newTokenServer(t, &requests)
+
+	cfg := OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: server.URL}
+	open := func(authURL string) error {
+		u, _ := url.Parse(authURL)
+		go func() {
+			resp, err := http.Get(u.Query().Get("redirect_uri") + "?code=auth-code&state=forged")
+			if err == nil {
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, _, err := LoginLoopback(ctx, cfg, "localhost:0", open); err == nil {
+		t.Fatal("Expected error for a forged state")
+	}
+	if len(requests) != 0 {
+		t.Errorf("Expected no token request, got %d", len(requests))
+	}
+}
+
+func TestOAuthTokenSource_RefreshesExpiredToken(t *testing.T) {
+	var requests []url.Values
+	server := newTokenServer(t, &requests)
+
+	cfg := OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: server.URL}
+	expired := &Token{AccessToken: "old", RefreshToken: "refresh-token", Expiry: time.Now().Add(-time.Hour)}
+
+	var saved []*Token
+	ts := NewOAuthTokenSource(cfg, expired, func(token *Token) error {
+		saved = append(saved, token)
+		return nil
+	})
+
+	for i := 0; i < 2; i++ {
+		token, err := ts.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token failed: %v", err)
+		}
+		if token != "access-1" {
+			t.Errorf("Expected access-1, got %s", token)
+		}
+	}
+
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 refresh, got %d", len(requests))
+	}
+	if requests[0].Get("grant_type") != "refresh_token" || requests[0].Get("refresh_token") != "refresh-token" {
+		t.Errorf("Unexpected refresh request: %v", requests[0])
+	}
+	if len(saved) != 1 || saved[0].AccessToken != "access-1" {
+		t.Errorf("Expected the refreshed token to be saved, got %v", saved)
+	}
+}
+
+func TestOAuthTokenSource_NoRefreshToken(t *testing.T) {
+	ts := NewOAuthTokenSource(OAuthConfig{}, &Token{AccessToken: "old"}, nil)
+	if _, err := ts.Token(context.Background()); err == nil {
+		t.Fatal("Expected error without a refresh token")
+	}
+}
+
+func TestClient_doRequestRefreshesRejectedToken(t *testing.T) {
+	var requests []url.Values
+	tokenServer := newTokenServer(t, &requests)
+
+	var auths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auths = append(auths, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "Bearer access-1" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"status":"error","message":"expired","category":"EXPIRED_AUTHENTICATION"}`))
+			return
+		}
+		w.Write([]byte(`{"id":"1","properties":{}}`))
+	}))
+	defer server.Close()
+
+	cfg := OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: tokenServer.URL}
+	// Still valid locally, but revoked on the server
+	token := &Token{AccessToken: "revoked", RefreshToken: "refresh-token", Expiry: time.Now().Add(time.Hour)}
+
+	client, _ := newTestClient(server.URL, WithTokenSource(NewOAuthTokenSource(cfg, token, nil)))
+	if _, err := client.GetObject(context.Background(), Contacts.Name, "1", nil); err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+
+	if len(auths) != 2 || auths[0] != "Bearer revoked" || auths[1] != "Bearer access-1" {
+		t.Errorf("Expected a retry with the refreshed token, got %v", auths)
+	}
+	if len(requests) != 1 {
+		t.Errorf("Expected 1 refresh, got %d", len(requests))
+	}
+}
+
+func TestClient_doRequestRefreshesOnlyOnce(t *testing.T) {
+	var requests []url.Values
+	tokenServer := newTokenServer(t, &requests)
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"status":"error","message":"nope"}`))
+	}))
+	defer server.Close()
+
+	cfg := OAuthConfig{ClientID: "client", ClientSecret: "secret", TokenURL: tokenServer.URL}
+	token := &Token{AccessToken: "revoked", RefreshToken: "refresh-token", Expiry: time.Now().Add(time.Hour)}
+
+	client, _ := newTestClient(server.URL, WithTokenSource(NewOAuthTokenSource(cfg, token, nil)))
+	_, err := client.GetObject(context.Background(), Contacts.Name, "1", nil)
+	apiErr, ok := AsAPIError(err)
+	if !ok || !apiErr.IsUnauthorized() {
+		t.Fatalf("Expected unauthorized error, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
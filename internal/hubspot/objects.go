@@ -0,0 +1,297 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// ObjectType describes a CRM object type and the properties shown by default
+type ObjectType struct {
+	// Name is the path segment used by the CRM objects API, e.g. "contacts" or "line_items"
+	Name string
+	// DefaultProperties are requested when the caller does not ask for specific properties
+	DefaultProperties []string
+	// SearchProperty is matched with CONTAINS_TOKEN by free-form queries
+	SearchProperty string
+}
+
+// Standard CRM object types
+var (
+	Contacts = ObjectType{
+		Name:              "contacts",
+		DefaultProperties: []string{"email", "firstname", "lastname", "company", "hs_lead_status", "lifecyclestage"},
+		SearchProperty:    "email",
+	}
+	Companies = ObjectType{
+		Name:              "companies",
+		DefaultProperties: []string{"name", "domain", "industry", "city", "country", "lifecyclestage"},
+		SearchProperty:    "name",
+	}
+	Deals = ObjectType{
+		Name:              "deals",
+		DefaultProperties: []string{"dealname", "amount", "dealstage", "pipeline", "closedate"},
+		SearchProperty:    "dealname",
+	}
+	Tickets = ObjectType{
+		Name:              "tickets",
+		DefaultProperties: []string{"subject", "hs_pipeline", "hs_pipeline_stage", "hs_ticket_priority", "createdate"},
+		SearchProperty:    "subject",
+	}
+	Products = ObjectType{
+		Name:              "products",
+		DefaultProperties: []string{"name", "price", "hs_sku", "description"},
+		SearchProperty:    "name",
+	}
+	LineItems = ObjectType{
+		Name:              "line_items",
+		DefaultProperties: []string{"name", "quantity", "price", "amount", "hs_product_id"},
+		SearchProperty:    "name",
+	}
+)
+
+// Object represents a HubSpot CRM object such as a contact, company or deal
+type Object struct {
+	ID         string                 `json:"id"`
+	Properties map[string]interface{} `json:"properties"`
+	CreatedAt  string                 `json:"createdAt"`
+	UpdatedAt  string                 `json:"updatedAt"`
+	Archived   bool                   `json:"archived,omitempty"`
+	ArchivedAt string                 `json:"archivedAt,omitempty"`
+	// New is set by batch upserts when the object was created rather than updated
+	New bool `json:"new,omitempty"`
+	// PropertiesWithHistory is only set when property history was requested
+	PropertiesWithHistory map[string][]PropertyVersion `json:"propertiesWithHistory,omitempty"`
+	// Associations is only set when associated object IDs were requested
+	Associations map[string]AssociatedObjects `json:"associations,omitempty"`
+}
+
+// ObjectResponse represents a page of objects returned by the HubSpot API
+type ObjectResponse struct {
+	Results []Object `json:"results"`
+	Total   int      `json:"total,omitempty"`
+	Paging  *Paging  `json:"paging,omitempty"`
+}
+
+// Filter represents a single search filter
+type Filter struct {
+	PropertyName string   `json:"propertyName"`
+	Operator     string   `json:"operator"`
+	Value        string   `json:"value,omitempty"`
+	HighValue    string   `json:"highValue,omitempty"`
+	Values       []string `json:"values,omitempty"`
+}
+
+// FilterGroup represents filters combined with AND; groups are combined with OR
+type FilterGroup struct {
+	Filters []Filter `json:"filters"`
+}
+
+// Limits HubSpot enforces on search requests
+const (
+	MaxFilterGroups    = 5
+	MaxFiltersPerGroup = 6
+	MaxFilters         = 18
+)
+
+// Sort directions
+const (
+	SortAscending  = "ASCENDING"
+	SortDescending = "DESCENDING"
+)
+
+// Sort orders search results by a property
+type Sort struct {
+	PropertyName string `json:"propertyName"`
+	Direction    string `json:"direction"`
+}
+
+// SearchRequest represents the body of a CRM search request
+type SearchRequest struct {
+	FilterGroups []FilterGroup `json:"filterGroups"`
+	Sorts        []Sort        `json:"sorts,omitempty"`
+	// Query is a free-text search across the object type's default searchable properties
+	Query      string   `json:"query,omitempty"`
+	Properties []string `json:"properties,omitempty"`
+	Limit      int      `json:"limit,omitempty"`
+	After      string   `json:"after,omitempty"`
+}
+
+// SimpleQuery builds filter groups from a "property=value" query, or matches
+// a bare value against searchProperty with CONTAINS_TOKEN
+func SimpleQuery(query, searchProperty string) []FilterGroup {
+	if strings.Contains(query, "=") {
+		parts := strings.SplitN(query, "=", 2)
+		return []FilterGroup{{Filters: []Filter{{
+			PropertyName: strings.TrimSpace(parts[0]),
+			Operator:     "EQ",
+			Value:        strings.TrimSpace(parts[1]),
+		}}}}
+	}
+
+	return []FilterGroup{{Filters: []Filter{{
+		PropertyName: searchProperty,
+		Operator:     "CONTAINS_TOKEN",
+		Value:        strings.TrimSpace(query),
+	}}}}
+}
+
+// objectsEndpoint returns the CRM objects endpoint for an object type, with optional path segments
+func objectsEndpoint(objectType string, segments ...string) string {
+	endpoint := "/crm/v3/objects/" + url.PathEscape(objectType)
+	for _, segment := range segments {
+		endpoint += "/" + url.PathEscape(segment)
+	}
+	return endpoint
+}
+
+// ListObjects retrieves a page of objects of the given type
+func (c *Client) ListObjects(ctx context.Context, objectType string, limit int, after string, properties []string) (*ObjectResponse, error) {
+	return c.listObjects(ctx, objectType, limit, after, properties, false)
+}
+
+// ListArchivedObjects retrieves a page of the archived objects of the given type
+func (c *Client) ListArchivedObjects(ctx context.Context, objectType string, limit int, after string, properties []string) (*ObjectResponse, error) {
+	return c.listObjects(ctx, objectType, limit, after, properties, true)
+}
+
+func (c *Client) listObjects(ctx context.Context, objectType string, limit int, after string, properties []string, archived bool) (*ObjectResponse, error) {
+	params := url.Values{}
+	params.Add("limit", fmt.Sprintf("%d", limit))
+	if after != "" {
+		params.Add("after", after)
+	}
+	if len(properties) > 0 {
+		params.Add("properties", strings.Join(properties, ","))
+	}
+	if archived {
+		params.Add("archived", "true")
+	}
+
+	respBody, err := c.doRequest(ctx, "GET", objectsEndpoint(objectType)+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var objectResp ObjectResponse
+	if err := json.Unmarshal(respBody, &objectResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &objectResp, nil
+}
+
+// GetObject retrieves a specific object by ID
+func (c *Client) GetObject(ctx context.Context, objectType, objectID string, properties []string) (*Object, error) {
+	return c.GetObjectWithAssociations(ctx, objectType, objectID, properties, nil)
+}
+
+// GetObjectWithAssociations retrieves a specific object by ID along with the
+// IDs of its associated objects of the given types
+func (c *Client) GetObjectWithAssociations(ctx context.Context, objectType, objectID string, properties, associations []string) (*Object, error) {
+	endpoint := objectsEndpoint(objectType, objectID)
+	params := url.Values{}
+	if len(properties) > 0 {
+		params.Add("properties", strings.Join(properties, ","))
+	}
+	if len(associations) > 0 {
+		params.Add("associations", strings.Join(associations, ","))
+	}
+	if len(params) > 0 {
+		endpoint += "?" + params.Encode()
+	}
+
+	respBody, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var object Object
+	if err := json.Unmarshal(respBody, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &object, nil
+}
+
+// CreateObject creates a new object of the given type
+func (c *Client) CreateObject(ctx context.Context, objectType string, properties map[string]interface{}) (*Object, error) {
+	requestBody := map[string]interface{}{
+		"properties": properties,
+	}
+
+	respBody, err := c.doRequest(ctx, "POST", objectsEndpoint(objectType), requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var object Object
+	if err := json.Unmarshal(respBody, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &object, nil
+}
+
+// UpdateObject updates an existing object
+func (c *Client) UpdateObject(ctx context.Context, objectType, objectID string, properties map[string]interface{}) (*Object, error) {
+	requestBody := map[string]interface{}{
+		"properties": properties,
+	}
+
+	respBody, err := c.doRequest(ctx, "PATCH", objectsEndpoint(objectType, objectID), requestBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var object Object
+	if err := json.Unmarshal(respBody, &object); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &object, nil
+}
+
+// DeleteObject archives an object by ID
+func (c *Client) DeleteObject(ctx context.Context, objectType, objectID string) error {
+	_, err := c.doRequest(ctx, "DELETE", objectsEndpoint(objectType, objectID), nil)
+	return err
+}
+
+// SearchObjects searches objects of the given type using HubSpot's search API
+func (c *Client) SearchObjects(ctx context.Context, objectType string, search SearchRequest) (*ObjectResponse, error) {
+	if search.FilterGroups == nil {
+		search.FilterGroups = []FilterGroup{}
+	}
+
+	respBody, err := c.doRequest(ctx, "POST", objectsEndpoint(objectType, "search"), search)
+	if err != nil {
+		return nil, err
+	}
+
+	var objectResp ObjectResponse
+	if err := json.Unmarshal(respBody, &objectResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &objectResp, nil
+}
+
+// ListObjectProperties retrieves all property definitions of an object type
+func (c *Client) ListObjectProperties(ctx context.Context, objectType string) ([]Property, error) {
+	endpoint := "/crm/v3/properties/" + url.PathEscape(objectType)
+
+	respBody, err := c.doRequest(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var propsResp PropertiesResponse
+	if err := json.Unmarshal(respBody, &propsResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return propsResp.Results, nil
+}
@@ -0,0 +1,121 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ListObjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/crm/v3/objects/line_items" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("properties"); got != "name,price" {
+			t.Errorf("Unexpected properties %q", got)
+		}
+		w.Write([]byte(`{"results":[{"id":"7","properties":{"name":"Widget"}}],"paging":{"next":{"after":"8"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	resp, err := client.ListObjects(context.Background(), LineItems.Name, 10, "", []string{"name", "price"})
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != "7" || resp.Paging.Next.After != "8" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestClient_ListArchivedObjects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("archived"); got != "true" {
+			t.Errorf("Expected archived=true, got %q", got)
+		}
+		w.Write([]byte(`{"results":[{"id":"7","properties":{},"archived":true,"archivedAt":"2024-05-01T10:00:00Z"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	resp, err := client.ListArchivedObjects(context.Background(), Contacts.Name, 100, "", nil)
+	if err != nil {
+		t.Fatalf("ListArchivedObjects failed: %v", err)
+	}
+	if len(resp.Results) != 1 || !resp.Results[0].Archived || resp.Results[0].ArchivedAt != "2024-05-01T10:00:00Z" {
+		t.Errorf("Unexpected response: %+v", resp)
+	}
+}
+
+func TestClient_SearchContacts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != "/crm/v3/objects/contacts/search" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var search SearchRequest
+		if err := json.NewDecoder(r.Body).Decode(&search); err != nil {
+			t.Fatalf("Failed to decode search request: %v", err)
+		}
+		filter := search.FilterGroups[0].Filters[0]
+		if filter.PropertyName != "lifecyclestage" || filter.Operator != "EQ" || filter.Value != "lead" {
+			t.Errorf("Unexpected filter: %+v", filter)
+		}
+		w.Write([]byte(`{"total":0,"results":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	if _, err := client.SearchContacts(context.Background(), "lifecyclestage = lead", 10); err != nil {
+		t.Fatalf("SearchContacts failed: %v", err)
+	}
+}
+
+func TestClient_SearchObjectsSortsAndQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode search request: %v", err)
+		}
+		if body["query"] != "jane" {
+			t.Errorf("Expected query jane, got %v", body["query"])
+		}
+		sorts, _ := body["sorts"].([]interface{})
+		if len(sorts) != 1 {
+			t.Fatalf("Expected 1 sort, got %v", body["sorts"])
+		}
+		sort := sorts[0].(map[string]interface{})
+		if sort["propertyName"] != "createdate" || sort["direction"] != SortDescending {
+			t.Errorf("Unexpected sort: %v", sort)
+		}
+		w.Write([]byte(`{"total":0,"results":[]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	_, err := client.SearchObjects(context.Background(), Contacts.Name, SearchRequest{
+		FilterGroups: []FilterGroup{},
+		Sorts:        []Sort{{PropertyName: "createdate", Direction: SortDescending}},
+		Query:        "jane",
+	})
+	if err != nil {
+		t.Fatalf("SearchObjects failed: %v", err)
+	}
+}
+
+func TestSimpleQuery(t *testing.T) {
+	groups := SimpleQuery("acme", Companies.SearchProperty)
+	filter := groups[0].Filters[0]
+	if filter.PropertyName != "name" || filter.Operator != "CONTAINS_TOKEN" || filter.Value != "acme" {
+		t.Errorf("Unexpected filter: %+v", filter)
+	}
+}
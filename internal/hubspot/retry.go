@@ -0,0 +1,191 @@
+package hubspot
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RetryPolicy controls how throttled and failed requests are retried
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt (0 disables retries)
+	MaxRetries int
+	// BaseDelay is the initial backoff delay, doubled on every retry
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay between two attempts. A longer
+	// Retry-After makes the request fail instead of waiting.
+	MaxDelay time.Duration
+}
+
+// DefaultRetryPolicy returns the retry policy used when none is configured
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxRetries: 4,
+		BaseDelay:  500 * time.Millisecond,
+		MaxDelay:   30 * time.Second,
+	}
+}
+
+// backoff returns the full-jitter delay before retry number attempt (starting at 0)
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || delay < p.MaxDelay); i++ {
+		delay *= 2
+	}
+	if p.MaxDelay > 0 && delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// retryableStatus reports whether a response status is worth retrying
+func retryableStatus(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retrySafe reports whether a request can be repeated after a server error or
+// a transport failure without risking duplicate writes. Throttled (429)
+// requests were never processed and are always retried.
+func retrySafe(method, endpoint string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	case http.MethodPost:
+		path := endpoint
+		if i := strings.Index(path, "?"); i >= 0 {
+			path = path[:i]
+		}
+		// Searches and every batch operation except create can safely be repeated
+		for _, suffix := range []string{"/search", "/batch/read", "/batch/update", "/batch/upsert", "/batch/archive"} {
+			if strings.HasSuffix(path, suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// retryAfter parses the Retry-After header, which holds either seconds or an HTTP date
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := t.Sub(now); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+// rateLimiter tracks HubSpot's X-HubSpot-RateLimit-* headers and spaces out
+// requests once the remaining budget in the current window runs low
+type rateLimiter struct {
+	mu        sync.Mutex
+	max       int
+	remaining int
+	resetAt   time.Time
+	secondly  int
+	secondAt  time.Time
+}
+
+// lowWatermark is the fraction of the window budget below which requests are spread out
+const lowWatermark = 0.1
+
+// observe records the rate limit headers of a response
+func (l *rateLimiter) observe(h http.Header, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if remaining, ok := headerInt(h, "X-HubSpot-RateLimit-Remaining"); ok {
+		l.remaining = remaining
+		l.max, _ = headerInt(h, "X-HubSpot-RateLimit-Max")
+		interval, ok := headerInt(h, "X-HubSpot-RateLimit-Interval-Milliseconds")
+		if !ok || interval <= 0 {
+			interval = 10000
+		}
+		l.resetAt = now.Add(time.Duration(interval) * time.Millisecond)
+	}
+	if remaining, ok := headerInt(h, "X-HubSpot-RateLimit-Secondly-Remaining"); ok {
+		l.secondly = remaining
+		l.secondAt = now.Add(time.Second)
+	}
+}
+
+// delay returns how long to wait before sending the next request
+func (l *rateLimiter) delay(now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var wait time.Duration
+	if !l.secondAt.IsZero() && now.Before(l.secondAt) && l.secondly <= 0 {
+		wait = l.secondAt.Sub(now)
+	}
+	if l.resetAt.IsZero() || !now.Before(l.resetAt) {
+		return wait
+	}
+
+	window := l.resetAt.Sub(now)
+	var d time.Duration
+	switch {
+	case l.remaining <= 0:
+		d = window
+	case l.max > 0 && float64(l.remaining) < float64(l.max)*lowWatermark:
+		// Spread the remaining budget evenly over the rest of the window
+		d = window / time.Duration(l.remaining+1)
+	}
+	if d > wait {
+		wait = d
+	}
+	return wait
+}
+
+func headerInt(h http.Header, key string) (int, bool) {
+	v := h.Get(key)
+	if v == "" {
+		return 0, false
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(v))
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
@@ -0,0 +1,213 @@
+package hubspot
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/obay/hscli/internal/cassette"
+)
+
+func newTestClient(url string, opts ...Option) (*Client, *[]time.Duration) {
+	var slept []time.Duration
+	client := NewClient("test-api-key", opts...)
+	client.baseURL = url
+	client.sleep = func(ctx context.Context, d time.Duration) error {
+		slept = append(slept, d)
+		return nil
+	}
+	return client, &slept
+}
+
+func TestClient_doRequestRetriesThrottled(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	client, slept := newTestClient(server.URL)
+	if _, err := client.doRequest(context.Background(), "POST", "/crm/v3/objects/contacts", map[string]string{"a": "b"}); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+	if len(*slept) != 2 || (*slept)[0] != 2*time.Second {
+		t.Errorf("Expected two 2s Retry-After waits, got %v", *slept)
+	}
+}
+
+func TestClient_doRequestRetryAfterBeyondMaxDelay(t *testing.T) {
+	tests := map[string]string{
+		"seconds":   "3600",
+		"http date": time.Now().Add(2 * time.Hour).UTC().Format(http.TimeFormat),
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			calls := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				w.Header().Set("Retry-After", value)
+				w.WriteHeader(http.StatusTooManyRequests)
+			}))
+			defer server.Close()
+
+			client, slept := newTestClient(server.URL)
+			_, err := client.doRequest(context.Background(), "GET", "/test", nil)
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+				t.Fatalf("Expected the 429 API error, got %v", err)
+			}
+			if calls != 1 || len(*slept) != 0 {
+				t.Errorf("Expected to give up without waiting, got %d calls and waits %v", calls, *slept)
+			}
+		})
+	}
+}
+
+func TestClient_doRequestGivesUp(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(server.URL, WithRetryPolicy(RetryPolicy{MaxRetries: 2, BaseDelay: time.Millisecond}))
+	if _, err := client.doRequest(context.Background(), "GET", "/test", nil); err == nil {
+		t.Fatal("Expected error after exhausting retries")
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", calls)
+	}
+}
+
+func TestClient_doRequestDoesNotRetryUnsafeWrites(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client, _ := newTestClient(server.URL)
+	if _, err := client.doRequest(context.Background(), "POST", "/crm/v3/objects/contacts", nil); err == nil {
+		t.Fatal("Expected error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected a single call for a failed create, got %d", calls)
+	}
+}
+
+func TestRetryPolicy_backoff(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt := 0; attempt < 10; attempt++ {
+		if d := policy.backoff(attempt); d < 0 || d > time.Second {
+			t.Errorf("backoff(%d) = %v, want within [0, 1s]", attempt, d)
+		}
+	}
+}
+
+func TestRateLimiter_delay(t *testing.T) {
+	now := time.Now()
+	h := http.Header{}
+	h.Set("X-HubSpot-RateLimit-Max", "100")
+	h.Set("X-HubSpot-RateLimit-Remaining", "50")
+	h.Set("X-HubSpot-RateLimit-Interval-Milliseconds", "10000")
+
+	l := &rateLimiter{}
+	l.observe(h, now)
+	if d := l.delay(now); d != 0 {
+		t.Errorf("Expected no delay with plenty of budget, got %v", d)
+	}
+
+	h.Set("X-HubSpot-RateLimit-Remaining", "4")
+	l.observe(h, now)
+	if d := l.delay(now); d != 2*time.Second {
+		t.Errorf("Expected 2s spacing with 4 requests left, got %v", d)
+	}
+
+	h.Set("X-HubSpot-RateLimit-Remaining", "0")
+	l.observe(h, now)
+	if d := l.delay(now); d != 10*time.Second {
+		t.Errorf("Expected to wait out the window, got %v", d)
+	}
+	if d := l.delay(now.Add(11 * time.Second)); d != 0 {
+		t.Errorf("Expected no delay after the window, got %v", d)
+	}
+}
+
+func TestClient_doRequestCancelledDuringBackoff(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "20")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.doRequest(ctx, "GET", "/test", nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Error("Cancellation did not interrupt the Retry-After wait")
+	}
+}
+
+func TestClient_doRequestReplay(t *testing.T) {
+	c := &cassette.Cassette{Interactions: []cassette.Interaction{
+		{
+			Request:  cassette.Request{Method: "GET", URL: "/test/1"},
+			Response: cassette.Response{StatusCode: http.StatusTooManyRequests, Headers: http.Header{"Retry-After": {"5"}}},
+		},
+		{
+			Request: cassette.Request{Method: "GET", URL: "/test/1"},
+			Response: cassette.Response{StatusCode: http.StatusOK, Body: `{}`, Headers: http.Header{
+				"X-Hubspot-Ratelimit-Remaining":             {"0"},
+				"X-Hubspot-Ratelimit-Max":                   {"100"},
+				"X-Hubspot-Ratelimit-Interval-Milliseconds": {"10000"},
+			}},
+		},
+	}}
+	calls := 0
+	replayer := cassette.NewReplayer(c)
+	client, slept := newTestClient("http://replay.invalid", WithReplay(roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		calls++
+		return replayer.RoundTrip(r)
+	})))
+
+	// The recorded retry is replayed without waiting for Retry-After
+	if _, err := client.doRequest(context.Background(), "GET", "/test/1", nil); err != nil {
+		t.Fatalf("doRequest failed: %v", err)
+	}
+
+	// A request missing from the cassette fails at once, without waiting for
+	// the recorded rate limit
+	_, err := client.doRequest(context.Background(), "GET", "/test/2", nil)
+	if !errors.Is(err, cassette.ErrNoInteraction) || !strings.Contains(err.Error(), "/test/2") {
+		t.Errorf("Expected a missing interaction error naming the request, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 replayed requests, got %d", calls)
+	}
+	if len(*slept) != 0 {
+		t.Errorf("Expected no waiting during replay, got %v", *slept)
+	}
+}
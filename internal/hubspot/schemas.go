@@ -0,0 +1,166 @@
+package hubspot
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// SchemaLabels holds the display labels of a custom object type
+type SchemaLabels struct {
+	Singular string `json:"singular"`
+	Plural   string `json:"plural"`
+}
+
+// ObjectSchema represents a custom object schema returned by the schemas API
+type ObjectSchema struct {
+	ID                         string       `json:"id"`
+	ObjectTypeID               string       `json:"objectTypeId"`
+	FullyQualifiedName         string       `json:"fullyQualifiedName"`
+	Name                       string       `json:"name"`
+	Labels                     SchemaLabels `json:"labels"`
+	PrimaryDisplayProperty     string       `json:"primaryDisplayProperty"`
+	SecondaryDisplayProperties []string     `json:"secondaryDisplayProperties,omitempty"`
+	SearchableProperties       []string     `json:"searchableProperties,omitempty"`
+	RequiredProperties         []string     `json:"requiredProperties,omitempty"`
+	Properties                 []Property   `json:"properties,omitempty"`
+	AssociatedObjects          []string     `json:"associatedObjects,omitempty"`
+	Archived                   bool         `json:"archived,omitempty"`
+	CreatedAt                  string       `json:"createdAt,omitempty"`
+	UpdatedAt                  string       `json:"updatedAt,omitempty"`
+}
+
+// SchemaDefinition represents the body used to create a custom object schema
+type SchemaDefinition struct {
+	Name                       string       `json:"name"`
+	Labels                     SchemaLabels `json:"labels"`
+	PrimaryDisplayProperty     string       `json:"primaryDisplayProperty"`
+	SecondaryDisplayProperties []string     `json:"secondaryDisplayProperties,omitempty"`
+	SearchableProperties       []string     `json:"searchableProperties,omitempty"`
+	RequiredProperties         []string     `json:"requiredProperties"`
+	Properties                 []Property   `json:"properties"`
+	AssociatedObjects          []string     `json:"associatedObjects"`
+}
+
+// SchemasResponse represents the response for schemas
+type SchemasResponse struct {
+	Results []ObjectSchema `json:"results"`
+}
+
+// ObjectType returns the object type used to address records of this schema
+func (s ObjectSchema) ObjectType() ObjectType {
+	properties := []string{}
+	if s.PrimaryDisplayProperty != "" {
+		properties = append(properties, s.PrimaryDisplayProperty)
+	}
+	for _, name := range s.SecondaryDisplayProperties {
+		if name != s.PrimaryDisplayProperty {
+			properties = append(properties, name)
+		}
+	}
+
+	return ObjectType{
+		Name:              s.ObjectTypeID,
+		DefaultProperties: properties,
+		SearchProperty:    s.PrimaryDisplayProperty,
+	}
+}
+
+// StandardObjectTypes lists the built-in CRM object types
+var StandardObjectTypes = []ObjectType{Contacts, Companies, Deals, Tickets, Products, LineItems}
+
+// ListSchemas retrieves all custom object schemas of the portal
+func (c *Client) ListSchemas(ctx context.Context) ([]ObjectSchema, error) {
+	respBody, err := c.doRequest(ctx, "GET", "/crm/v3/schemas", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemasResp SchemasResponse
+	if err := json.Unmarshal(respBody, &schemasResp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return schemasResp.Results, nil
+}
+
+// GetSchema retrieves a custom object schema by object type ID or fully qualified name
+func (c *Client) GetSchema(ctx context.Context, objectType string) (*ObjectSchema, error) {
+	respBody, err := c.doRequest(ctx, "GET", "/crm/v3/schemas/"+url.PathEscape(objectType), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema ObjectSchema
+	if err := json.Unmarshal(respBody, &schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &schema, nil
+}
+
+// CreateSchema creates a new custom object schema
+func (c *Client) CreateSchema(ctx context.Context, definition SchemaDefinition) (*ObjectSchema, error) {
+	if definition.RequiredProperties == nil {
+		definition.RequiredProperties = []string{}
+	}
+	if definition.AssociatedObjects == nil {
+		definition.AssociatedObjects = []string{}
+	}
+
+	respBody, err := c.doRequest(ctx, "POST", "/crm/v3/schemas", definition)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema ObjectSchema
+	if err := json.Unmarshal(respBody, &schema); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return &schema, nil
+}
+
+// DeleteSchema deletes a custom object schema; HubSpot requires all of its records to be deleted first
+func (c *Client) DeleteSchema(ctx context.Context, objectType string) error {
+	_, err := c.doRequest(ctx, "DELETE", "/crm/v3/schemas/"+url.PathEscape(objectType), nil)
+	return err
+}
+
+// ResolveObjectType resolves a standard object name, a custom object type ID
+// (e.g. "2-123456"), or a custom object name or label (e.g. "subscriptions")
+// into an object type. The schema is returned for custom objects and is nil
+// for standard ones.
+func (c *Client) ResolveObjectType(ctx context.Context, nameOrID string) (ObjectType, *ObjectSchema, error) {
+	key := strings.ToLower(strings.TrimSpace(nameOrID))
+	for _, objectType := range StandardObjectTypes {
+		if key == objectType.Name || key == strings.ReplaceAll(objectType.Name, "_", "-") {
+			return objectType, nil, nil
+		}
+	}
+
+	schemas, err := c.ListSchemas(ctx)
+	if err != nil {
+		return ObjectType{}, nil, fmt.Errorf("failed to list schemas: %w", err)
+	}
+
+	for i := range schemas {
+		schema := &schemas[i]
+		candidates := []string{
+			schema.ObjectTypeID,
+			schema.FullyQualifiedName,
+			schema.Name,
+			schema.Labels.Singular,
+			schema.Labels.Plural,
+		}
+		for _, candidate := range candidates {
+			if candidate != "" && strings.ToLower(candidate) == key {
+				return schema.ObjectType(), schema, nil
+			}
+		}
+	}
+
+	return ObjectType{}, nil, fmt.Errorf("unknown object type %q", nameOrID)
+}
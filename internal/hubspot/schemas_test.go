@@ -0,0 +1,55 @@
+package hubspot
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClient_ResolveObjectType(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/crm/v3/schemas" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"results":[{
+			"objectTypeId": "2-123456",
+			"fullyQualifiedName": "p42_subscriptions",
+			"name": "subscriptions",
+			"labels": {"singular": "Subscription", "plural": "Subscriptions"},
+			"primaryDisplayProperty": "plan",
+			"secondaryDisplayProperties": ["status"]
+		}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.baseURL = server.URL
+
+	objectType, schema, err := client.ResolveObjectType(context.Background(), "line-items")
+	if err != nil || schema != nil || objectType.Name != "line_items" {
+		t.Errorf("Expected standard line_items type, got %+v, %v, %v", objectType, schema, err)
+	}
+	if calls != 0 {
+		t.Errorf("Standard types should resolve without calling the API")
+	}
+
+	for _, name := range []string{"subscriptions", "Subscription", "2-123456", "p42_subscriptions"} {
+		objectType, schema, err := client.ResolveObjectType(context.Background(), name)
+		if err != nil {
+			t.Fatalf("ResolveObjectType(%q) failed: %v", name, err)
+		}
+		if schema == nil || objectType.Name != "2-123456" || objectType.SearchProperty != "plan" {
+			t.Errorf("ResolveObjectType(%q) = %+v", name, objectType)
+		}
+		if len(objectType.DefaultProperties) != 2 || objectType.DefaultProperties[1] != "status" {
+			t.Errorf("Unexpected default properties %v", objectType.DefaultProperties)
+		}
+	}
+
+	if _, _, err := client.ResolveObjectType(context.Background(), "devices"); err == nil {
+		t.Error("Expected error for unknown object type")
+	}
+}
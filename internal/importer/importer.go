@@ -0,0 +1,381 @@
+// Package importer loads CSV files into HubSpot with batch upserts. Rows are
+// validated against the portal's property definitions before anything is
+// sent, and rows HubSpot rejects are reported with its error message.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// Row is a data row of the CSV file
+type Row struct {
+	// Line is the row's line number in the file, counting the header as line 1
+	Line int
+	// Record holds the row's original values
+	Record []string
+	// Key is the value of the ID property that identifies the record
+	Key string
+	// Properties are the validated values of the mapped columns
+	Properties map[string]interface{}
+	// Error says why the row was rejected; it is empty for valid rows
+	Error string
+}
+
+// File is a parsed and validated CSV file
+type File struct {
+	Header []string
+	Rows   []*Row
+	// Columns maps column indexes to the properties they are imported into
+	Columns map[int]string
+	// Ignored lists the columns that are not imported
+	Ignored []string
+}
+
+// Valid returns the rows that passed validation
+func (f *File) Valid() []*Row {
+	var rows []*Row
+	for _, row := range f.Rows {
+		if row.Error == "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// Rejected returns the rows that failed validation or were rejected by HubSpot
+func (f *File) Rejected() []*Row {
+	var rows []*Row
+	for _, row := range f.Rows {
+		if row.Error != "" {
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+// ParseMapping parses a column mapping such as "E-mail=email,First=firstname"
+func ParseMapping(spec string) (map[string]string, error) {
+	mapping := make(map[string]string)
+	if strings.TrimSpace(spec) == "" {
+		return mapping, nil
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		column, property, ok := strings.Cut(entry, "=")
+		column, property = strings.TrimSpace(column), strings.TrimSpace(property)
+		if !ok || column == "" || property == "" {
+			return nil, fmt.Errorf("invalid mapping %q, expected column=property", strings.TrimSpace(entry))
+		}
+		mapping[column] = property
+	}
+	return mapping, nil
+}
+
+// Read parses a CSV file with a header line and validates every row. Columns
+// are imported into the property given by mapping; unmapped columns whose
+// header matches a property's name or label are imported into it and the
+// rest are ignored. Each row must have a value for idProperty.
+func Read(r io.Reader, mapping map[string]string, definitions []hubspot.Property, idProperty string) (*File, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("the CSV file is empty")
+		}
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	if len(header) > 0 {
+		header[0] = strings.TrimPrefix(header[0], "\ufeff")
+	}
+
+	byName := make(map[string]hubspot.Property, len(definitions))
+	byLabel := make(map[string]hubspot.Property, len(definitions))
+	for _, def := range definitions {
+		byName[strings.ToLower(def.Name)] = def
+		byLabel[strings.ToLower(def.Label)] = def
+	}
+
+	file := &File{Header: header, Columns: make(map[int]string)}
+	defs := make(map[int]hubspot.Property)
+	mapped := make(map[string]bool)
+	for i, column := range header {
+		column = strings.TrimSpace(column)
+
+		name, ok := lookupMapping(mapping, column)
+		if ok {
+			mapped[strings.ToLower(column)] = true
+		} else if def, found := byName[strings.ToLower(column)]; found {
+			name = def.Name
+		} else if def, found := byLabel[strings.ToLower(column)]; found && column != "" {
+			name = def.Name
+		} else {
+			file.Ignored = append(file.Ignored, column)
+			continue
+		}
+
+		def, found := byName[strings.ToLower(name)]
+		if !found {
+			return nil, fmt.Errorf("column %q is mapped to unknown property %q", column, name)
+		}
+		if def.ReadOnly() {
+			return nil, fmt.Errorf("column %q is mapped to read-only property %q", column, def.Name)
+		}
+		for _, other := range file.Columns {
+			if other == def.Name {
+				return nil, fmt.Errorf("more than one column is mapped to property %q", def.Name)
+			}
+		}
+		file.Columns[i] = def.Name
+		defs[i] = def
+	}
+
+	for column := range mapping {
+		if !mapped[strings.ToLower(column)] {
+			return nil, fmt.Errorf("column %q of the mapping is not in the CSV header", column)
+		}
+	}
+
+	keyColumn := -1
+	for i, name := range file.Columns {
+		if name == idProperty {
+			keyColumn = i
+		}
+	}
+	if keyColumn < 0 {
+		return nil, fmt.Errorf("no column is mapped to %s, which identifies the records", idProperty)
+	}
+
+	seen := make(map[string]int)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if blank(record) {
+			continue
+		}
+		line, _ := reader.FieldPos(0)
+
+		row := &Row{Line: line, Record: record, Properties: make(map[string]interface{})}
+		file.Rows = append(file.Rows, row)
+
+		var problems []string
+		for i, name := range file.Columns {
+			if i >= len(record) {
+				continue
+			}
+			value := strings.TrimSpace(record[i])
+			if value == "" {
+				// Empty cells leave the existing value alone
+				continue
+			}
+			normalized, err := Validate(defs[i], value)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: %v", name, err))
+				continue
+			}
+			row.Properties[name] = normalized
+		}
+
+		if keyColumn < len(record) {
+			row.Key = strings.TrimSpace(record[keyColumn])
+		}
+		switch {
+		case row.Key == "":
+			problems = append(problems, fmt.Sprintf("%s is required", idProperty))
+		default:
+			key := strings.ToLower(row.Key)
+			if first, ok := seen[key]; ok {
+				problems = append(problems, fmt.Sprintf("duplicate %s %s, first seen on line %d", idProperty, row.Key, first))
+			} else {
+				seen[key] = line
+			}
+		}
+
+		if len(problems) > 0 {
+			sort.Strings(problems)
+			row.Error = strings.Join(problems, "; ")
+		}
+	}
+
+	return file, nil
+}
+
+// Upserter creates or updates records in batches
+type Upserter interface {
+	BatchUpsert(ctx context.Context, objectType string, inputs []hubspot.BatchInput) (*hubspot.BatchResult, error)
+}
+
+// Summary counts the outcome of an import
+type Summary struct {
+	Created  int
+	Updated  int
+	Rejected int
+}
+
+// Import upserts rows in batches, identifying records by idProperty. HubSpot
+// rejects a whole batch when one of its records is invalid, so rejected
+// batches are split and resent until each rejection is pinned to its row;
+// the rejected rows get HubSpot's error message. An error is only returned
+// when the import cannot continue, e.g. for an authentication failure.
+func Import(ctx context.Context, client Upserter, objectType, idProperty string, rows []*Row) (Summary, error) {
+	var summary Summary
+	for start := 0; start < len(rows); start += hubspot.MaxBatchSize {
+		end := min(start+hubspot.MaxBatchSize, len(rows))
+		if err := upsert(ctx, client, objectType, idProperty, rows[start:end], &summary); err != nil {
+			return summary, err
+		}
+	}
+	return summary, nil
+}
+
+func upsert(ctx context.Context, client Upserter, objectType, idProperty string, rows []*Row, summary *Summary) error {
+	inputs := make([]hubspot.BatchInput, len(rows))
+	for i, row := range rows {
+		inputs[i] = hubspot.BatchInput{ID: row.Key, IDProperty: idProperty, Properties: row.Properties}
+	}
+
+	result, err := client.BatchUpsert(ctx, objectType, inputs)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range result.Results {
+		if object.New {
+			summary.Created++
+		} else {
+			summary.Updated++
+		}
+	}
+
+	// Errors tied to records come first, so that an error HubSpot could not
+	// tie to records only covers the rows no other error accounts for
+	handled := make(map[int]bool)
+	var untied []hubspot.BatchError
+	for _, batchErr := range result.Errors {
+		if len(batchErr.Inputs) == 0 {
+			untied = append(untied, batchErr)
+			continue
+		}
+		if err := reject(ctx, client, objectType, idProperty, rows, pending(batchErr.Inputs, handled), batchErr.Message, summary); err != nil {
+			return err
+		}
+	}
+	if len(untied) > 0 {
+		// Whatever the number of such errors, the rows missing from the
+		// results are resent once
+		indexes := pending(unresolved(rows, result), handled)
+		if err := reject(ctx, client, objectType, idProperty, rows, indexes, untied[0].Message, summary); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pending returns the indexes not handled yet and marks them handled
+func pending(indexes []int, handled map[int]bool) []int {
+	var fresh []int
+	for _, i := range indexes {
+		if !handled[i] {
+			handled[i] = true
+			fresh = append(fresh, i)
+		}
+	}
+	return fresh
+}
+
+// reject pins an error to a single row, or splits the rows in two and
+// resends them to find out which of them are invalid
+func reject(ctx context.Context, client Upserter, objectType, idProperty string, rows []*Row, indexes []int, message string, summary *Summary) error {
+	switch len(indexes) {
+	case 0:
+		return nil
+	case 1:
+		rows[indexes[0]].Error = message
+		summary.Rejected++
+		return nil
+	}
+
+	failed := make([]*Row, len(indexes))
+	for i, index := range indexes {
+		failed[i] = rows[index]
+	}
+	half := len(failed) / 2
+	if err := upsert(ctx, client, objectType, idProperty, failed[:half], summary); err != nil {
+		return err
+	}
+	return upsert(ctx, client, objectType, idProperty, failed[half:], summary)
+}
+
+// unresolved returns the indexes of rows missing from a batch's results
+func unresolved(rows []*Row, result *hubspot.BatchResult) []int {
+	done := make(map[string]bool)
+	for _, object := range result.Results {
+		for _, value := range object.Properties {
+			if s, ok := value.(string); ok {
+				done[strings.ToLower(s)] = true
+			}
+		}
+	}
+
+	var indexes []int
+	for i, row := range rows {
+		if !done[strings.ToLower(row.Key)] {
+			indexes = append(indexes, i)
+		}
+	}
+	return indexes
+}
+
+// WriteRejected writes the rejected rows as CSV with their original columns
+// and an error column, so they can be fixed and imported again
+func WriteRejected(w io.Writer, header []string, rows []*Row) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(append(append([]string(nil), header...), "error")); err != nil {
+		return err
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		copy(record, row.Record)
+		if err := writer.Write(append(record, row.Error)); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// lookupMapping finds the property a column is mapped to, ignoring case
+func lookupMapping(mapping map[string]string, column string) (string, bool) {
+	if name, ok := mapping[column]; ok {
+		return name, true
+	}
+	for key, name := range mapping {
+		if strings.EqualFold(key, column) {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+func blank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
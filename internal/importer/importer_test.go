@@ -0,0 +1,227 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/obay/hscli/hubspottest"
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+var definitions = []hubspot.Property{
+	{Name: "email", Label: "Email", Type: "string", FieldType: "text"},
+	{Name: "firstname", Label: "First Name", Type: "string", FieldType: "text"},
+	{Name: "company", Label: "Company Name", Type: "string", FieldType: "text"},
+	{Name: "lifecyclestage", Label: "Lifecycle Stage", Type: "enumeration", FieldType: "radio", Options: []hubspot.PropertyOption{
+		{Label: "Lead", Value: "lead"},
+		{Label: "Marketing Qualified Lead", Value: "marketingqualifiedlead"},
+	}},
+	{Name: "numberofemployees", Label: "Employees", Type: "number", FieldType: "number"},
+	{Name: "createdate", Label: "Create Date", Type: "datetime", FieldType: "date",
+		ModificationMetadata: &hubspot.PropertyModificationMetadata{ReadOnlyValue: true}},
+}
+
+func TestParseMapping(t *testing.T) {
+	mapping, err := ParseMapping("E-mail=email, First = firstname")
+	if err != nil {
+		t.Fatalf("ParseMapping failed: %v", err)
+	}
+	if mapping["E-mail"] != "email" || mapping["First"] != "firstname" {
+		t.Errorf("Unexpected mapping: %v", mapping)
+	}
+
+	if _, err := ParseMapping("E-mail"); err == nil {
+		t.Error("Expected error for an entry without =")
+	}
+}
+
+func TestRead(t *testing.T) {
+	csv := "\ufeffE-mail,First,Company Name,Stage,Badge\n" +
+		"ada@example.com,Ada,\"Engines, Ltd\",Marketing Qualified Lead,A1\n" +
+		"grace@example.com,Grace,,customer,A2\n" +
+		",Alan,,,A3\n" +
+		"\n" +
+		"ADA@example.com,Ada,,,A4\n" +
+		"not-an-email,Kat,,,A5\n"
+
+	mapping := map[string]string{"E-mail": "email", "First": "firstname", "stage": "lifecyclestage"}
+	file, err := Read(strings.NewReader(csv), mapping, definitions, "email")
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+
+	if len(file.Ignored) != 1 || file.Ignored[0] != "Badge" {
+		t.Errorf("Expected the Badge column to be ignored, got %v", file.Ignored)
+	}
+	if len(file.Rows) != 5 {
+		t.Fatalf("Expected 5 rows, got %d", len(file.Rows))
+	}
+
+	ada := file.Rows[0]
+	if ada.Error != "" || ada.Key != "ada@example.com" {
+		t.Errorf("Expected a valid first row, got %+v", ada)
+	}
+	if ada.Properties["company"] != "Engines, Ltd" || ada.Properties["lifecyclestage"] != "marketingqualifiedlead" {
+		t.Errorf("Unexpected properties: %v", ada.Properties)
+	}
+
+	tests := []struct {
+		line int
+		want string
+	}{
+		{3, `lifecyclestage: "customer" is not one of the allowed options`},
+		{4, "email is required"},
+		{6, "duplicate email ADA@example.com, first seen on line 2"},
+		{7, `"not-an-email" is not a valid email address`},
+	}
+	for i, tt := range tests {
+		row := file.Rows[i+1]
+		if row.Line != tt.line || !strings.Contains(row.Error, tt.want) {
+			t.Errorf("Expected line %d to fail with %q, got line %d: %q", tt.line, tt.want, row.Line, row.Error)
+		}
+	}
+	if len(file.Valid()) != 1 || len(file.Rejected()) != 4 {
+		t.Errorf("Expected 1 valid and 4 rejected rows, got %d and %d", len(file.Valid()), len(file.Rejected()))
+	}
+}
+
+func TestRead_MappingErrors(t *testing.T) {
+	tests := []struct {
+		csv     string
+		mapping map[string]string
+		want    string
+	}{
+		{"Mail,Name\n", map[string]string{"Mail": "email", "Name": "nickname"}, "unknown property"},
+		{"Mail,Created\n", map[string]string{"Mail": "email", "Created": "createdate"}, "read-only"},
+		{"Mail\n", map[string]string{"Mail": "email", "Phone": "phone"}, "not in the CSV header"},
+		{"First\n", nil, "no column is mapped to email"},
+		{"email,Email\n", nil, "more than one column"},
+		{"", nil, "empty"},
+	}
+	for _, tt := range tests {
+		_, err := Read(strings.NewReader(tt.csv), tt.mapping, definitions, "email")
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%q: expected error containing %q, got %v", tt.csv, tt.want, err)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		def     hubspot.Property
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{definitions[3], "LEAD", "lead", false},
+		{definitions[3], "Lead", "lead", false},
+		{definitions[4], "12", "12", false},
+		{definitions[4], "twelve", "", true},
+		{hubspot.Property{Type: "bool"}, "Yes", "true", false},
+		{hubspot.Property{Type: "date"}, "2024-03-05", "2024-03-05", false},
+		{hubspot.Property{Type: "date"}, "05/03/2024", "", true},
+		{hubspot.Property{Type: "datetime"}, "2024-03-05T10:00:00+01:00", "2024-03-05T09:00:00Z", false},
+		{hubspot.Property{Type: "enumeration", FieldType: "checkbox", Options: definitions[3].Options}, "lead; Marketing Qualified Lead", "lead;marketingqualifiedlead", false},
+	}
+	for _, tt := range tests {
+		got, err := Validate(tt.def, tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("Validate(%s, %q): expected %q (error %v), got %q (%v)", tt.def.Type, tt.value, tt.want, tt.wantErr, got, err)
+		}
+	}
+}
+
+func TestImport(t *testing.T) {
+	mock := hubspottest.New()
+	mock.AddObject("contacts", map[string]string{"email": "ada@example.com", "firstname": "Ada"})
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := hubspot.NewClient("test", hubspot.WithBaseURL(server.URL))
+
+	var rows []*Row
+	for i := 0; i < 150; i++ {
+		email := fmt.Sprintf("lead%d@example.com", i)
+		rows = append(rows, &Row{Line: i + 2, Key: email, Properties: map[string]interface{}{"email": email}})
+	}
+	rows[0].Key, rows[0].Properties = "ada@example.com", map[string]interface{}{"lastname": "Lovelace"}
+	// The mock rejects a batch with an invalid option as a whole; the import
+	// has to split the batch to pin the rejection to these rows
+	rows[42].Properties["lifecyclestage"] = "bogus"
+	rows[120].Properties["numberofemployees"] = "many"
+
+	summary, err := Import(context.Background(), client, "contacts", "email", rows)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+
+	if summary.Created != 147 || summary.Updated != 1 || summary.Rejected != 2 {
+		t.Errorf("Expected 147 created, 1 updated and 2 rejected, got %+v", summary)
+	}
+	if !strings.Contains(rows[42].Error, "allowed options") || rows[120].Error == "" {
+		t.Errorf("Expected HubSpot's errors on the invalid rows, got %q and %q", rows[42].Error, rows[120].Error)
+	}
+	if rows[41].Error != "" || rows[43].Error != "" {
+		t.Errorf("Expected neighbouring rows to be imported, got %q and %q", rows[41].Error, rows[43].Error)
+	}
+	if mock.Count("contacts") != 148 {
+		t.Errorf("Expected 148 contacts, got %d", mock.Count("contacts"))
+	}
+}
+
+// untiedUpserter rejects batches containing a bad key with several errors
+// that are not tied to inputs, and creates the records of other batches
+type untiedUpserter struct {
+	calls int
+}
+
+func (u *untiedUpserter) BatchUpsert(ctx context.Context, objectType string, inputs []hubspot.BatchInput) (*hubspot.BatchResult, error) {
+	u.calls++
+	result := &hubspot.BatchResult{}
+	for _, input := range inputs {
+		if strings.HasPrefix(input.ID, "bad") {
+			result.Results = nil
+			result.Errors = []hubspot.BatchError{{Message: "invalid record"}, {Message: "another problem"}}
+			return result, nil
+		}
+		result.Results = append(result.Results, hubspot.Object{New: true, Properties: map[string]interface{}{"email": input.ID}})
+	}
+	return result, nil
+}
+
+func TestImport_UntiedErrors(t *testing.T) {
+	rows := []*Row{
+		{Line: 2, Key: "ada@example.com"},
+		{Line: 3, Key: "bad@example.com"},
+	}
+	client := &untiedUpserter{}
+
+	summary, err := Import(context.Background(), client, "contacts", "email", rows)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	// The batch, then each row once
+	if client.calls != 3 {
+		t.Errorf("Expected 3 calls, got %d", client.calls)
+	}
+	if summary != (Summary{Created: 1, Rejected: 1}) {
+		t.Errorf("Expected 1 created and 1 rejected, got %+v", summary)
+	}
+	if rows[0].Error != "" || rows[1].Error != "invalid record" {
+		t.Errorf("Expected only the bad row to be rejected, got %q and %q", rows[0].Error, rows[1].Error)
+	}
+}
+
+func TestWriteRejected(t *testing.T) {
+	var out strings.Builder
+	rows := []*Row{{Record: []string{"ada@example.com", "Engines, Ltd"}, Error: "company: too long"}}
+	if err := WriteRejected(&out, []string{"E-mail", "Company"}, rows); err != nil {
+		t.Fatalf("WriteRejected failed: %v", err)
+	}
+	want := "E-mail,Company,error\nada@example.com,\"Engines, Ltd\",company: too long\n"
+	if out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+}
@@ -0,0 +1,111 @@
+package importer
+
+import (
+	"fmt"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// Validate checks a CSV value against a property definition and returns it
+// in the form HubSpot expects, e.g. an enumeration option's value for its
+// label or "true" for "yes"
+func Validate(def hubspot.Property, value string) (string, error) {
+	switch def.Type {
+	case "enumeration":
+		if def.FieldType == "checkbox" && len(def.Options) > 0 {
+			// Multiple checkbox values are separated by semicolons
+			var values []string
+			for _, part := range strings.Split(value, ";") {
+				option, err := matchOption(def, strings.TrimSpace(part))
+				if err != nil {
+					return "", err
+				}
+				values = append(values, option)
+			}
+			return strings.Join(values, ";"), nil
+		}
+		if def.FieldType == "booleancheckbox" {
+			return parseBool(value)
+		}
+		if len(def.Options) > 0 {
+			return matchOption(def, value)
+		}
+
+	case "bool":
+		return parseBool(value)
+
+	case "number":
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return "", fmt.Errorf("%q is not a number", value)
+		}
+
+	case "date":
+		if t, ok := parseTime(value); ok {
+			return t.Format("2006-01-02"), nil
+		}
+		return "", fmt.Errorf("%q is not a date, expected YYYY-MM-DD", value)
+
+	case "datetime":
+		if t, ok := parseTime(value); ok {
+			return t.UTC().Format(time.RFC3339), nil
+		}
+		if _, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return value, nil
+		}
+		return "", fmt.Errorf("%q is not a date and time, expected RFC 3339 or YYYY-MM-DD", value)
+	}
+
+	if def.Name == "email" && !validEmail(value) {
+		return "", fmt.Errorf("%q is not a valid email address", value)
+	}
+	return value, nil
+}
+
+// matchOption returns the option whose value or label matches, ignoring case
+func matchOption(def hubspot.Property, value string) (string, error) {
+	for _, opt := range def.Options {
+		if opt.Value == value {
+			return opt.Value, nil
+		}
+	}
+	for _, opt := range def.Options {
+		if strings.EqualFold(opt.Value, value) || strings.EqualFold(opt.Label, value) {
+			return opt.Value, nil
+		}
+	}
+
+	values := make([]string, len(def.Options))
+	for i, opt := range def.Options {
+		values[i] = opt.Value
+	}
+	return "", fmt.Errorf("%q is not one of the allowed options: %s", value, strings.Join(values, ", "))
+}
+
+func parseBool(value string) (string, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1":
+		return "true", nil
+	case "false", "no", "n", "0":
+		return "false", nil
+	}
+	return "", fmt.Errorf("%q is not true or false", value)
+}
+
+func parseTime(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// validEmail reports whether value is a bare email address
+func validEmail(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value && strings.Contains(value[strings.LastIndex(value, "@"):], ".")
+}
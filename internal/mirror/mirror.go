@@ -0,0 +1,214 @@
+// Package mirror keeps a local copy of a portal's objects of one type on
+// disk. The first sync pulls every object; later syncs only fetch the
+// objects modified since the newest modification seen so far, and drop the
+// objects that were archived in HubSpot.
+package mirror
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// State describes the last sync of a mirror
+type State struct {
+	ObjectType string   `json:"objectType"`
+	Properties []string `json:"properties"`
+	// Watermark is the newest modification date of the mirrored objects,
+	// but no later than shortly before the last sync started; the next sync
+	// fetches the objects modified since
+	Watermark time.Time `json:"watermark"`
+	SyncedAt  time.Time `json:"syncedAt"`
+	Count     int       `json:"count"`
+}
+
+// Mirror is the local copy of the objects of one type
+type Mirror struct {
+	State   State
+	dir     string
+	objects map[string]hubspot.Object
+}
+
+// Open loads the mirror of objectType kept in dir. A mirror that was never
+// synced is empty.
+func Open(dir, objectType string) (*Mirror, error) {
+	m := &Mirror{
+		State:   State{ObjectType: objectType},
+		dir:     dir,
+		objects: make(map[string]hubspot.Object),
+	}
+
+	data, err := os.ReadFile(m.statePath())
+	if errors.Is(err, fs.ErrNotExist) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror state: %w", err)
+	}
+	if err := json.Unmarshal(data, &m.State); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror state %s: %w", m.statePath(), err)
+	}
+
+	f, err := os.Open(m.objectsPath())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mirror: %w", err)
+	}
+	defer f.Close()
+
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for {
+		var object hubspot.Object
+		if err := dec.Decode(&object); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read mirror %s: %w", m.objectsPath(), err)
+		}
+		m.objects[object.ID] = object
+	}
+	return m, nil
+}
+
+// Synced reports whether the mirror has been synced at least once
+func (m *Mirror) Synced() bool {
+	return !m.State.SyncedAt.IsZero()
+}
+
+// Len returns the number of mirrored objects
+func (m *Mirror) Len() int {
+	return len(m.objects)
+}
+
+// Get returns a mirrored object by ID
+func (m *Mirror) Get(id string) (hubspot.Object, bool) {
+	object, ok := m.objects[id]
+	return object, ok
+}
+
+// Objects returns the mirrored objects ordered by ID, like HubSpot lists them
+func (m *Mirror) Objects() []hubspot.Object {
+	objects := make([]hubspot.Object, 0, len(m.objects))
+	for _, object := range m.objects {
+		objects = append(objects, object)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		return lessID(objects[i].ID, objects[j].ID)
+	})
+	return objects
+}
+
+// Missing returns the properties that are not mirrored
+func (m *Mirror) Missing(properties []string) []string {
+	var missing []string
+	for _, name := range properties {
+		if !slices.Contains(m.State.Properties, name) && !alwaysReturned[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+// alwaysReturned lists the properties HubSpot returns whether or not they
+// were requested
+var alwaysReturned = map[string]bool{"hs_object_id": true, "createdate": true, "lastmodifieddate": true}
+
+// Select returns a copy of object with only the given properties and those
+// HubSpot always returns, as if it had been read with these properties
+func Select(object hubspot.Object, properties []string) hubspot.Object {
+	selected := make(map[string]interface{}, len(properties)+len(alwaysReturned))
+	for name := range alwaysReturned {
+		if value, ok := object.Properties[name]; ok {
+			selected[name] = value
+		}
+	}
+	for _, name := range properties {
+		selected[name] = object.Properties[name]
+	}
+	object.Properties = selected
+	return object
+}
+
+// Save writes the mirror to disk. The objects are written before the state,
+// so an interrupted save leaves the old watermark and the next sync fetches
+// the same changes again.
+func (m *Mirror) Save() error {
+	if err := os.MkdirAll(m.dir, 0o700); err != nil {
+		return fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	err := writeFile(m.objectsPath(), func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		for _, object := range m.Objects() {
+			if err := enc.Encode(object); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save mirror: %w", err)
+	}
+
+	m.State.Count = len(m.objects)
+	err = writeFile(m.statePath(), func(w io.Writer) error {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(m.State)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to save mirror state: %w", err)
+	}
+	return nil
+}
+
+func (m *Mirror) objectsPath() string {
+	return filepath.Join(m.dir, m.State.ObjectType+".ndjson")
+}
+
+func (m *Mirror) statePath() string {
+	return filepath.Join(m.dir, m.State.ObjectType+".state.json")
+}
+
+// writeFile replaces path with what write produces, through a temporary
+// file so readers never see a partial file
+func writeFile(path string, write func(io.Writer) error) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := write(w); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// lessID orders numeric IDs numerically and others as strings
+func lessID(a, b string) bool {
+	na, errA := strconv.ParseInt(a, 10, 64)
+	nb, errB := strconv.ParseInt(b, 10, 64)
+	if errA == nil && errB == nil {
+		return na < nb
+	}
+	return a < b
+}
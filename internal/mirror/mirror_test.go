@@ -0,0 +1,172 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/obay/hscli/hubspottest"
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+func TestSync(t *testing.T) {
+	mock := hubspottest.New()
+	for i := 0; i < 150; i++ {
+		mock.AddObject("contacts", map[string]string{"email": fmt.Sprintf("lead%d@example.com", i), "firstname": "Lead"})
+	}
+	server := httptest.NewServer(mock)
+	defer server.Close()
+	client := hubspot.NewClient("test", hubspot.WithBaseURL(server.URL))
+	ctx := context.Background()
+	dir := t.TempDir()
+	opts := Options{Properties: []string{"email", "firstname"}, ModifiedProperty: "lastmodifieddate"}
+
+	m, err := Open(dir, "contacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Synced() {
+		t.Fatal("Expected a new mirror not to be synced")
+	}
+
+	result, err := m.Sync(ctx, client, opts)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !result.Full || result.Added != 150 || m.Len() != 150 {
+		t.Fatalf("Expected a full sync adding 150 contacts, got %+v with %d", result, m.Len())
+	}
+
+	updated, err := client.UpdateContact(ctx, "1", map[string]interface{}{"firstname": "Ada"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := client.DeleteContact(ctx, "2"); err != nil {
+		t.Fatal(err)
+	}
+	created, err := client.CreateContact(ctx, map[string]interface{}{"email": "grace@example.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen from disk, like the next run of hscli sync
+	m, err = Open(dir, "contacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	requests := mock.Requests()
+	result, err = m.Sync(ctx, client, opts)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if result.Full || result.Added != 1 || result.Updated != 1 || result.Archived != 1 {
+		t.Errorf("Expected an incremental sync with 1 added, 1 updated and 1 archived, got %+v", result)
+	}
+	// One search page and one page of archived contacts
+	if n := mock.Requests() - requests; n != 2 {
+		t.Errorf("Expected 2 requests for the delta, got %d", n)
+	}
+
+	m, err = Open(dir, "contacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Len() != 150 || m.State.Count != 150 {
+		t.Errorf("Expected 150 mirrored contacts, got %d (state says %d)", m.Len(), m.State.Count)
+	}
+	if object, _ := m.Get(updated.ID); object.Properties["firstname"] != "Ada" {
+		t.Errorf("Expected the updated first name, got %v", object.Properties["firstname"])
+	}
+	if _, ok := m.Get("2"); ok {
+		t.Error("Expected the archived contact to be removed")
+	}
+	if _, ok := m.Get(created.ID); !ok {
+		t.Error("Expected the created contact to be mirrored")
+	}
+	if objects := m.Objects(); objects[0].ID != "1" || objects[len(objects)-1].ID != created.ID {
+		t.Errorf("Expected objects ordered by ID, got %s..%s", objects[0].ID, objects[len(objects)-1].ID)
+	}
+
+	// Mirroring another property pulls everything again
+	opts.Properties = append(opts.Properties, "lastname")
+	result, err = m.Sync(ctx, client, opts)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if !result.Full || result.Added != 0 || result.Archived != 0 {
+		t.Errorf("Expected a full sync without changes, got %+v", result)
+	}
+}
+
+func TestSync_ModifiedDuringPull(t *testing.T) {
+	mock := hubspottest.New()
+	for i := 0; i < 150; i++ {
+		mock.AddObject("contacts", map[string]string{"email": fmt.Sprintf("lead%d@example.com", i), "firstname": "Lead"})
+	}
+
+	// After the first page is served, modify a contact on it and then one
+	// on the second page, which the pull reads with the later date
+	var client *hubspot.Client
+	pages := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mock.ServeHTTP(w, r)
+		if r.Method == http.MethodGet && r.URL.Path == "/crm/v3/objects/contacts" {
+			if pages++; pages == 1 {
+				modify(t, client, "1", "Ada")
+				time.Sleep(5 * time.Millisecond)
+				modify(t, client, "150", "Grace")
+			}
+		}
+	}))
+	defer server.Close()
+	client = hubspot.NewClient("test", hubspot.WithBaseURL(server.URL))
+	ctx := context.Background()
+	opts := Options{Properties: []string{"email", "firstname"}, ModifiedProperty: "lastmodifieddate"}
+
+	m, err := Open(t.TempDir(), "contacts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Sync(ctx, client, opts); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if object, _ := m.Get("1"); object.Properties["firstname"] != "Lead" {
+		t.Fatalf("Expected the first page to be read before the change, got %v", object.Properties["firstname"])
+	}
+
+	result, err := m.Sync(ctx, client, opts)
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if object, _ := m.Get("1"); object.Properties["firstname"] != "Ada" {
+		t.Errorf("Expected the next sync to fetch the change made during the pull, got %v (%+v)", object.Properties["firstname"], result)
+	}
+}
+
+func modify(t *testing.T, client *hubspot.Client, id, firstname string) {
+	t.Helper()
+	if _, err := client.UpdateContact(context.Background(), id, map[string]interface{}{"firstname": firstname}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMissingAndSelect(t *testing.T) {
+	m := &Mirror{State: State{Properties: []string{"email", "firstname"}}}
+	if missing := m.Missing([]string{"email", "createdate", "phone"}); len(missing) != 1 || missing[0] != "phone" {
+		t.Errorf("Expected phone to be missing, got %v", missing)
+	}
+
+	object := hubspot.Object{ID: "1", Properties: map[string]interface{}{
+		"email": "ada@example.com", "firstname": "Ada", "hs_object_id": "1",
+	}}
+	selected := Select(object, []string{"email"})
+	if len(selected.Properties) != 2 || selected.Properties["email"] != "ada@example.com" || selected.Properties["hs_object_id"] != "1" {
+		t.Errorf("Expected email and hs_object_id, got %v", selected.Properties)
+	}
+	if object.Properties["firstname"] != "Ada" {
+		t.Error("Expected Select to leave the original object alone")
+	}
+}
@@ -0,0 +1,237 @@
+package mirror
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+const (
+	listPageSize   = 100
+	searchPageSize = 200
+	// searchWindow is the number of results HubSpot returns for one search;
+	// larger deltas are fetched in several searches
+	searchWindow = 10000
+	// watermarkMargin is how far before the start of a sync the next one
+	// searches from at the latest, to allow for clock skew with HubSpot
+	watermarkMargin = time.Minute
+)
+
+// Options controls a sync
+type Options struct {
+	// Properties are the properties to mirror; a sync with other properties
+	// than the last one pulls every object again
+	Properties []string
+	// ModifiedProperty holds the objects' last modification date, e.g.
+	// lastmodifieddate for contacts
+	ModifiedProperty string
+	// Full pulls every object instead of only the modified ones
+	Full bool
+	// Progress, if set, is called with the number of objects fetched so far
+	Progress func(fetched int)
+}
+
+// Result counts the changes a sync made to the mirror
+type Result struct {
+	Full     bool
+	Added    int
+	Updated  int
+	Archived int
+}
+
+// Sync brings the mirror up to date and saves it. The first sync, or one
+// with Full set or different properties, lists every object. Later syncs
+// search for the objects modified since the watermark and remove the
+// objects HubSpot lists as archived. The mirror is left unchanged when the
+// sync fails.
+func (m *Mirror) Sync(ctx context.Context, client *hubspot.Client, opts Options) (Result, error) {
+	properties := opts.Properties
+	if len(properties) == 0 {
+		properties = m.State.Properties
+	}
+	request := properties
+	if !slices.Contains(request, opts.ModifiedProperty) {
+		request = append(slices.Clone(request), opts.ModifiedProperty)
+	}
+
+	started := time.Now().UTC()
+	var (
+		result Result
+		err    error
+	)
+	if opts.Full || !m.Synced() || !sameProperties(properties, m.State.Properties) {
+		result, err = m.pull(ctx, client, request, opts)
+	} else {
+		result, err = m.delta(ctx, client, request, opts)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// Objects read early in the sync may have been modified while it ran,
+	// before others with a later modification date were read, so the next
+	// sync must search from before this one started
+	if limit := started.Add(-watermarkMargin); m.State.Watermark.After(limit) {
+		m.State.Watermark = limit
+	}
+	m.State.Properties = properties
+	m.State.SyncedAt = started
+	return result, m.Save()
+}
+
+// pull replaces the mirror with every object listed by HubSpot
+func (m *Mirror) pull(ctx context.Context, client *hubspot.Client, request []string, opts Options) (Result, error) {
+	result := Result{Full: true}
+	objects := make(map[string]hubspot.Object, len(m.objects))
+	var watermark time.Time
+
+	it := client.ListIterator(ctx, m.State.ObjectType, listPageSize, request)
+	for it.Next() {
+		object := it.Object()
+		m.count(&result, object)
+		objects[object.ID] = object
+		watermark = later(watermark, modified(object, opts.ModifiedProperty))
+		progress(opts, len(objects))
+	}
+	if err := it.Err(); err != nil {
+		return result, fmt.Errorf("failed to list %s: %w", m.State.ObjectType, err)
+	}
+
+	for id := range m.objects {
+		if _, ok := objects[id]; !ok {
+			result.Archived++
+		}
+	}
+	m.objects = objects
+	m.State.Watermark = watermark
+	return result, nil
+}
+
+// delta applies the objects modified since the watermark and removes the
+// archived ones
+func (m *Mirror) delta(ctx context.Context, client *hubspot.Client, request []string, opts Options) (Result, error) {
+	var result Result
+	changes := make(map[string]hubspot.Object)
+	watermark := m.State.Watermark
+
+	// Search from the watermark inclusively, since several objects can share
+	// a modification time. HubSpot stops returning results after 10,000, so
+	// a larger delta is fetched in windows starting at the newest
+	// modification seen.
+	from := watermark
+	for {
+		it := client.SearchIterator(ctx, m.State.ObjectType, hubspot.SearchRequest{
+			FilterGroups: []hubspot.FilterGroup{{Filters: []hubspot.Filter{{
+				PropertyName: opts.ModifiedProperty,
+				Operator:     "GTE",
+				Value:        strconv.FormatInt(from.UnixMilli(), 10),
+			}}}},
+			Sorts:      []hubspot.Sort{{PropertyName: opts.ModifiedProperty, Direction: hubspot.SortAscending}},
+			Properties: request,
+			Limit:      searchPageSize,
+		})
+
+		n := 0
+		for n < searchWindow && it.Next() {
+			object := it.Object()
+			changes[object.ID] = object
+			watermark = later(watermark, modified(object, opts.ModifiedProperty))
+			n++
+			progress(opts, len(changes))
+		}
+		if err := it.Err(); err != nil {
+			return result, fmt.Errorf("failed to search modified %s: %w", m.State.ObjectType, err)
+		}
+		if n < searchWindow {
+			break
+		}
+		if !watermark.After(from) {
+			return result, fmt.Errorf("more than %d %s were modified at %s, run a full sync instead",
+				searchWindow, m.State.ObjectType, from.Format(time.RFC3339Nano))
+		}
+		from = watermark
+	}
+
+	var archived []string
+	it := client.ArchivedIterator(ctx, m.State.ObjectType, listPageSize, nil)
+	for it.Next() {
+		id := it.Object().ID
+		// An object modified since its archival was restored
+		if _, restored := changes[id]; !restored {
+			archived = append(archived, id)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return result, fmt.Errorf("failed to list archived %s: %w", m.State.ObjectType, err)
+	}
+
+	for _, object := range changes {
+		m.count(&result, object)
+		m.objects[object.ID] = object
+	}
+	for _, id := range archived {
+		if _, ok := m.objects[id]; ok {
+			delete(m.objects, id)
+			result.Archived++
+		}
+	}
+	m.State.Watermark = watermark
+	return result, nil
+}
+
+// count records whether object is new or changed compared to the mirror
+func (m *Mirror) count(result *Result, object hubspot.Object) {
+	old, ok := m.objects[object.ID]
+	switch {
+	case !ok:
+		result.Added++
+	case !reflect.DeepEqual(old.Properties, object.Properties):
+		result.Updated++
+	}
+}
+
+// modified returns the last modification date of an object
+func modified(object hubspot.Object, property string) time.Time {
+	value, _ := object.Properties[property].(string)
+	if value == "" {
+		value = object.UpdatedAt
+	}
+	if t, err := time.Parse(time.RFC3339Nano, value); err == nil {
+		return t
+	}
+	if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.UnixMilli(ms).UTC()
+	}
+	return time.Time{}
+}
+
+func later(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
+
+func progress(opts Options, fetched int) {
+	if opts.Progress != nil {
+		opts.Progress(fetched)
+	}
+}
+
+// sameProperties reports whether a and b hold the same properties in any order
+func sameProperties(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, name := range a {
+		if !slices.Contains(b, name) {
+			return false
+		}
+	}
+	return true
+}
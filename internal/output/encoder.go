@@ -0,0 +1,189 @@
+// Package output encodes command results in the machine-readable output
+// formats. Values are written one at a time, so long paginated results can
+// be streamed without holding them in memory.
+package output
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Output formats accepted by --format
+const (
+	Table  = "table"
+	JSON   = "json"
+	NDJSON = "ndjson"
+	CSV    = "csv"
+	TSV    = "tsv"
+	YAML   = "yaml"
+)
+
+// Formats lists the output formats in the order they are documented
+var Formats = []string{Table, JSON, NDJSON, CSV, TSV, YAML}
+
+// Usage is the help text of the --format flag
+var Usage = fmt.Sprintf("Output format (%s)", strings.Join(Formats, ", "))
+
+// Validate reports an error for an unknown output format
+func Validate(format string) error {
+	for _, f := range Formats {
+		if format == f {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(Formats, ", "))
+}
+
+// Encoder writes values in one of the machine-readable formats. JSON, NDJSON
+// and YAML encode the value itself; CSV and TSV write the row, under a
+// header line of the column names.
+type Encoder struct {
+	w       io.Writer
+	format  string
+	headers []string
+	csv     *csv.Writer
+	count   int
+
+	skipHeader bool
+}
+
+// NewEncoder returns an encoder for format, which must not be Table
+func NewEncoder(w io.Writer, format string, headers []string) *Encoder {
+	e := &Encoder{w: w, format: format, headers: headers}
+	if format == CSV {
+		e.csv = csv.NewWriter(w)
+	}
+	return e
+}
+
+// SkipHeader leaves out the CSV and TSV header line, e.g. when appending to
+// a file that already has one
+func (e *Encoder) SkipHeader() {
+	e.skipHeader = true
+}
+
+// Encode writes one value. row holds its column values for CSV and TSV.
+func (e *Encoder) Encode(value interface{}, row []string) error {
+	defer func() { e.count++ }()
+
+	switch e.format {
+	case JSON:
+		data, err := json.MarshalIndent(value, "  ", "  ")
+		if err != nil {
+			return err
+		}
+		sep := ",\n"
+		if e.count == 0 {
+			sep = "[\n"
+		}
+		_, err = fmt.Fprintf(e.w, "%s  %s", sep, data)
+		return err
+
+	case NDJSON:
+		data, err := json.Marshal(value)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(e.w, "%s\n", data)
+		return err
+
+	case YAML:
+		node, err := yamlNode(value)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(&yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{node}})
+		if err != nil {
+			return err
+		}
+		_, err = e.w.Write(data)
+		return err
+
+	case CSV, TSV:
+		if e.count == 0 && !e.skipHeader {
+			if err := e.writeRow(e.headers); err != nil {
+				return err
+			}
+		}
+		return e.writeRow(row)
+	}
+	return Validate(e.format)
+}
+
+// Close terminates the output, e.g. closes the JSON array. CSV and TSV
+// output still gets its header line when nothing was encoded.
+func (e *Encoder) Close() error {
+	switch e.format {
+	case JSON:
+		if e.count == 0 {
+			_, err := fmt.Fprintln(e.w, "[]")
+			return err
+		}
+		_, err := fmt.Fprint(e.w, "\n]\n")
+		return err
+	case YAML:
+		if e.count == 0 {
+			_, err := fmt.Fprintln(e.w, "[]")
+			return err
+		}
+	case CSV, TSV:
+		if e.count == 0 && !e.skipHeader {
+			return e.writeRow(e.headers)
+		}
+	}
+	return nil
+}
+
+// writeRow writes and flushes one CSV or TSV line, so rows stream as they
+// are encoded
+func (e *Encoder) writeRow(fields []string) error {
+	if e.csv != nil {
+		if err := e.csv.Write(fields); err != nil {
+			return err
+		}
+		e.csv.Flush()
+		return e.csv.Error()
+	}
+
+	escaped := make([]string, len(fields))
+	for i, field := range fields {
+		escaped[i] = tsvEscaper.Replace(field)
+	}
+	_, err := fmt.Fprintln(e.w, strings.Join(escaped, "\t"))
+	return err
+}
+
+// tsvEscaper backslash-escapes the characters that would break a TSV line
+var tsvEscaper = strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+
+// yamlNode converts value to a YAML node through its JSON encoding, so YAML
+// output uses the same field names and key order as JSON output
+func yamlNode(value interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	var doc yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		return nil, err
+	}
+	node := doc.Content[0]
+	plainStyle(node)
+	return node, nil
+}
+
+// plainStyle drops the JSON flow and quoting styles, leaving the encoder to
+// quote only the strings that need it
+func plainStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		plainStyle(child)
+	}
+}
@@ -0,0 +1,136 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+type record struct {
+	ID         string            `json:"id"`
+	Properties map[string]string `json:"properties"`
+}
+
+var records = []record{
+	{ID: "1", Properties: map[string]string{"email": "ada@example.com", "note": "says \"hi\", twice"}},
+	{ID: "2", Properties: map[string]string{"email": "grace@example.com", "note": "line one\nline\ttwo"}},
+}
+
+func encode(t *testing.T, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, format, []string{"id", "email", "note"})
+	for _, r := range records {
+		if err := enc.Encode(r, []string{r.ID, r.Properties["email"], r.Properties["note"]}); err != nil {
+			t.Fatalf("Encode failed: %v", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestEncoder_JSON(t *testing.T) {
+	var got []record
+	if err := json.Unmarshal([]byte(encode(t, JSON)), &got); err != nil {
+		t.Fatalf("Output is not a JSON array: %v", err)
+	}
+	if len(got) != 2 || got[1].Properties["note"] != records[1].Properties["note"] {
+		t.Errorf("Unexpected records: %+v", got)
+	}
+}
+
+func TestEncoder_NDJSON(t *testing.T) {
+	lines := strings.Split(strings.TrimSuffix(encode(t, NDJSON), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 lines, got %d: %q", len(lines), lines)
+	}
+	for i, line := range lines {
+		var got record
+		if err := json.Unmarshal([]byte(line), &got); err != nil {
+			t.Fatalf("Line %d is not JSON: %v", i, err)
+		}
+		if got.ID != records[i].ID {
+			t.Errorf("Line %d: expected ID %s, got %s", i, records[i].ID, got.ID)
+		}
+	}
+}
+
+func TestEncoder_CSV(t *testing.T) {
+	want := "id,email,note\n" +
+		"1,ada@example.com,\"says \"\"hi\"\", twice\"\n" +
+		"2,grace@example.com,\"line one\nline\ttwo\"\n"
+	if got := encode(t, CSV); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestEncoder_TSV(t *testing.T) {
+	want := "id\temail\tnote\n" +
+		"1\tada@example.com\tsays \"hi\", twice\n" +
+		"2\tgrace@example.com\tline one\\nline\\ttwo\n"
+	if got := encode(t, TSV); got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestEncoder_YAML(t *testing.T) {
+	got := encode(t, YAML)
+	for _, want := range []string{
+		"- id: \"1\"\n",
+		"  properties:\n",
+		"    email: ada@example.com\n",
+		"- id: \"2\"\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected output to contain %q, got\n%s", want, got)
+		}
+	}
+}
+
+func TestEncoder_Empty(t *testing.T) {
+	tests := map[string]string{
+		JSON:   "[]\n",
+		NDJSON: "",
+		YAML:   "[]\n",
+		CSV:    "id,email\n",
+		TSV:    "id\temail\n",
+	}
+	for format, want := range tests {
+		var buf bytes.Buffer
+		if err := NewEncoder(&buf, format, []string{"id", "email"}).Close(); err != nil {
+			t.Fatalf("%s: Close failed: %v", format, err)
+		}
+		if buf.String() != want {
+			t.Errorf("%s: expected %q, got %q", format, want, buf.String())
+		}
+	}
+}
+
+func TestEncoder_SkipHeader(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf, CSV, []string{"id", "email"})
+	enc.SkipHeader()
+	if err := enc.Encode(nil, []string{"1", "ada@example.com"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := enc.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "1,ada@example.com\n"; buf.String() != want {
+		t.Errorf("Expected %q, got %q", want, buf.String())
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, format := range Formats {
+		if err := Validate(format); err != nil {
+			t.Errorf("Validate(%s) failed: %v", format, err)
+		}
+	}
+	if err := Validate("xml"); err == nil {
+		t.Error("Expected error for an unknown format")
+	}
+}
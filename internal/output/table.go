@@ -0,0 +1,290 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/width"
+)
+
+// columnGap separates table columns
+const columnGap = "  "
+
+// minColumnWidth is the narrowest a column is shrunk to fit the table width
+const minColumnWidth = 6
+
+// DefaultSampleRows is how many rows a table buffers to size its columns
+// before it starts streaming
+const DefaultSampleRows = 200
+
+// TableOptions control how a table is laid out
+type TableOptions struct {
+	// Width is the maximum line width, usually the terminal width. Zero
+	// sizes columns to their content without limit.
+	Width int
+	// Wrap breaks long cells onto continuation lines instead of
+	// truncating them with an ellipsis
+	Wrap bool
+	// NoHeaders leaves out the header line and its rule
+	NoHeaders bool
+	// SampleRows is how many rows are buffered to size the columns. Later
+	// rows are streamed with the same widths. Zero buffers every row.
+	SampleRows int
+}
+
+// TableWriter renders rows as columns sized to their content. Cell widths are
+// measured in terminal cells, so wide and combining characters align.
+type TableWriter struct {
+	w       io.Writer
+	headers []string
+	opts    TableOptions
+	rows    [][]string
+	widths  []int
+}
+
+// NewTableWriter returns a table with the given column headers
+func NewTableWriter(w io.Writer, headers []string, opts TableOptions) *TableWriter {
+	return &TableWriter{w: w, headers: headers, opts: opts}
+}
+
+// Append adds a row. Rows are buffered until the sample is complete and
+// written immediately after that.
+func (t *TableWriter) Append(row ...string) error {
+	cells := make([]string, len(t.headers))
+	for i := range cells {
+		if i < len(row) {
+			cells[i] = sanitize(row[i])
+		}
+	}
+
+	if t.widths != nil {
+		return t.writeRow(cells)
+	}
+	t.rows = append(t.rows, cells)
+	if t.opts.SampleRows > 0 && len(t.rows) >= t.opts.SampleRows {
+		return t.Flush()
+	}
+	return nil
+}
+
+// Flush sizes the columns if that has not happened yet and writes the
+// buffered rows
+func (t *TableWriter) Flush() error {
+	if t.widths == nil {
+		t.widths = t.layout()
+		if !t.opts.NoHeaders {
+			if err := t.writeRow(t.headers); err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(t.w, strings.Repeat("-", t.lineWidth())); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, row := range t.rows {
+		if err := t.writeRow(row); err != nil {
+			return err
+		}
+	}
+	t.rows = nil
+	return nil
+}
+
+// layout returns the column widths: the widest cell of each column,
+// shrinking the widest columns first when the table would exceed opts.Width
+func (t *TableWriter) layout() []int {
+	natural := make([]int, len(t.headers))
+	for i, header := range t.headers {
+		if !t.opts.NoHeaders {
+			natural[i] = StringWidth(header)
+		}
+	}
+	for _, row := range t.rows {
+		for i, cell := range row {
+			if w := StringWidth(cell); w > natural[i] {
+				natural[i] = w
+			}
+		}
+	}
+
+	available := t.opts.Width - len(columnGap)*(len(natural)-1)
+	if t.opts.Width <= 0 || sum(natural) <= available {
+		return natural
+	}
+
+	// Find the largest cap that fits, then hand the remainder to the capped columns
+	limit := minColumnWidth
+	for c := minColumnWidth; ; c++ {
+		total, capped := 0, false
+		for _, w := range natural {
+			total += min(w, c)
+			capped = capped || w > c
+		}
+		if total > available || !capped {
+			break
+		}
+		limit = c
+	}
+
+	widths := make([]int, len(natural))
+	for i, w := range natural {
+		widths[i] = min(w, limit)
+	}
+	spare := available - sum(widths)
+	for i := range widths {
+		if spare <= 0 {
+			break
+		}
+		if natural[i] > widths[i] {
+			widths[i]++
+			spare--
+		}
+	}
+	return widths
+}
+
+// lineWidth is the width of a full table line
+func (t *TableWriter) lineWidth() int {
+	return sum(t.widths) + len(columnGap)*(len(t.widths)-1)
+}
+
+// writeRow writes a row, truncating or wrapping cells to the column widths
+func (t *TableWriter) writeRow(row []string) error {
+	lines := make([][]string, len(row))
+	height := 1
+	for i, cell := range row {
+		switch {
+		case t.opts.Wrap:
+			lines[i] = wrap(cell, t.widths[i])
+		case t.opts.Width > 0:
+			lines[i] = []string{Truncate(cell, t.widths[i])}
+		default:
+			// Without a width limit, rows streamed after the sample keep
+			// their full values even when that breaks the alignment
+			lines[i] = []string{cell}
+		}
+		height = max(height, len(lines[i]))
+	}
+
+	var b strings.Builder
+	for line := 0; line < height; line++ {
+		var out strings.Builder
+		for i := range row {
+			if i > 0 {
+				out.WriteString(columnGap)
+			}
+			text := ""
+			if line < len(lines[i]) {
+				text = lines[i][line]
+			}
+			out.WriteString(text)
+			out.WriteString(strings.Repeat(" ", max(0, t.widths[i]-StringWidth(text))))
+		}
+		b.WriteString(strings.TrimRight(out.String(), " "))
+		b.WriteByte('\n')
+	}
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+// RuneWidth returns the number of terminal cells r occupies
+func RuneWidth(r rune) int {
+	switch {
+	case r == 0 || unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || unicode.Is(unicode.Cf, r):
+		return 0
+	case unicode.IsControl(r):
+		return 0
+	}
+	switch width.LookupRune(r).Kind() {
+	case width.EastAsianWide, width.EastAsianFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns the number of terminal cells s occupies
+func StringWidth(s string) int {
+	n := 0
+	for _, r := range s {
+		n += RuneWidth(r)
+	}
+	return n
+}
+
+// Truncate shortens s to at most w terminal cells, marking the cut with an ellipsis
+func Truncate(s string, w int) string {
+	if StringWidth(s) <= w {
+		return s
+	}
+	if w <= 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	used := 0
+	for _, r := range s {
+		rw := RuneWidth(r)
+		if used+rw > w-1 {
+			break
+		}
+		b.WriteRune(r)
+		used += rw
+	}
+	b.WriteRune('…')
+	return b.String()
+}
+
+// wrap breaks s into lines of at most w terminal cells, preferring to
+// break at spaces
+func wrap(s string, w int) []string {
+	if w <= 0 || StringWidth(s) <= w {
+		return []string{s}
+	}
+
+	var lines []string
+	var line []rune
+	used := 0
+	lastSpace := -1
+	for _, r := range s {
+		rw := RuneWidth(r)
+		if used+rw > w {
+			if lastSpace > 0 {
+				rest := line[lastSpace+1:]
+				lines = append(lines, string(line[:lastSpace]))
+				line = append([]rune(nil), rest...)
+			} else {
+				lines = append(lines, string(line))
+				line = nil
+			}
+			used = StringWidth(string(line))
+			lastSpace = -1
+		}
+		if r == ' ' {
+			lastSpace = len(line)
+		}
+		line = append(line, r)
+		used += rw
+	}
+	return append(lines, string(line))
+}
+
+// sanitize replaces the line breaks and tabs that would break a table row
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\n' || r == '\r' || r == '\t' {
+			return ' '
+		}
+		return r
+	}, s)
+}
+
+func sum(values []int) int {
+	total := 0
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,120 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func render(t *testing.T, opts TableOptions, headers []string, rows ...[]string) string {
+	t.Helper()
+	var out strings.Builder
+	table := NewTableWriter(&out, headers, opts)
+	for _, row := range rows {
+		if err := table.Append(row...); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	if err := table.Flush(); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	return out.String()
+}
+
+func TestTable_SizesToContent(t *testing.T) {
+	got := render(t, TableOptions{}, []string{"ID", "Email"},
+		[]string{"1", "ada@example.com"},
+		[]string{"1002", "al@example.com"},
+	)
+	want := "ID    Email\n" +
+		"---------------------\n" +
+		"1     ada@example.com\n" +
+		"1002  al@example.com\n"
+	if got != want {
+		t.Errorf("Expected\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestTable_UnicodeWidth(t *testing.T) {
+	got := render(t, TableOptions{NoHeaders: true}, []string{"Name", "City"},
+		[]string{"山田太郎", "Tokyo"},
+		[]string{"José", "Madrid"},
+		[]string{"Zoë", "Paris"},
+	)
+	for _, line := range strings.Split(strings.TrimSuffix(got, "\n"), "\n") {
+		name, _, _ := strings.Cut(line, "  ")
+		if StringWidth(line)-StringWidth(strings.TrimLeft(line[len(name):], " ")) != 10 {
+			t.Errorf("Misaligned line %q", line)
+		}
+	}
+}
+
+func TestTable_TruncatesToWidth(t *testing.T) {
+	got := render(t, TableOptions{Width: 30, NoHeaders: true}, []string{"ID", "Email", "Company"},
+		[]string{"1", "a.very.long.email.address@example.com", "Analytical Engines Limited"},
+	)
+	line := strings.TrimSuffix(got, "\n")
+	if StringWidth(line) > 30 {
+		t.Errorf("Line exceeds 30 cells: %q", line)
+	}
+	if !strings.HasPrefix(line, "1  ") || strings.Count(line, "…") != 2 {
+		t.Errorf("Expected both long cells truncated, got %q", line)
+	}
+}
+
+func TestTable_Wrap(t *testing.T) {
+	got := render(t, TableOptions{Width: 20, Wrap: true, NoHeaders: true}, []string{"ID", "Note"},
+		[]string{"1", "the quick brown fox jumps"},
+	)
+	want := "1  the quick brown\n" +
+		"   fox jumps\n"
+	if got != want {
+		t.Errorf("Expected\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestTable_StreamsAfterSample(t *testing.T) {
+	var out strings.Builder
+	table := NewTableWriter(&out, []string{"ID"}, TableOptions{Width: 80, SampleRows: 2, NoHeaders: true})
+	table.Append("1")
+	if out.Len() != 0 {
+		t.Fatal("Expected rows to be buffered until the sample is complete")
+	}
+	table.Append("2")
+	table.Append("12345")
+	if out.String() != "1\n2\n…\n" {
+		t.Errorf("Expected streamed rows to keep the sampled widths, got %q", out.String())
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		in   string
+		w    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello world", 6, "hello…"},
+		{"山田太郎", 5, "山田…"},
+		{"abc", 0, ""},
+	}
+	for _, tt := range tests {
+		if got := Truncate(tt.in, tt.w); got != tt.want {
+			t.Errorf("Truncate(%q, %d): expected %q, got %q", tt.in, tt.w, tt.want, got)
+		}
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	tests := map[string]int{
+		"abc": 3,
+		"山田":  4,
+		"é":  1,
+		"ｈｉ":  4,
+		"Zoë": 3,
+	}
+	for in, want := range tests {
+		if got := StringWidth(in); got != want {
+			t.Errorf("StringWidth(%q): expected %d, got %d", in, want, got)
+		}
+	}
+}
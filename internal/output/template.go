@@ -0,0 +1,100 @@
+package output
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// Funcs are the helper functions available to output templates. They take
+// the piped value last, e.g. {{index .Properties "createdate" | date "2006-01-02"}}.
+var Funcs = template.FuncMap{
+	"date":    formatDate,
+	"default": defaultValue,
+	"join":    join,
+	"split":   split,
+	"lower":   strings.ToLower,
+	"upper":   strings.ToUpper,
+}
+
+// ParseTemplate parses an output template with the helper functions
+func ParseTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("output").Funcs(Funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid output template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// dateLayouts are the timestamp layouts HubSpot returns
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02"}
+
+// formatDate formats a HubSpot timestamp with a Go time layout. Timestamps
+// may be RFC 3339 strings, dates or milliseconds since the epoch; other
+// values are returned unchanged.
+func formatDate(layout string, value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format(layout)
+	case float64:
+		return time.UnixMilli(int64(v)).UTC().Format(layout)
+	case string:
+		if v == "" {
+			return ""
+		}
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.UnixMilli(ms).UTC().Format(layout)
+		}
+		for _, l := range dateLayouts {
+			if t, err := time.Parse(l, v); err == nil {
+				return t.Format(layout)
+			}
+		}
+		return v
+	}
+	return fmt.Sprint(value)
+}
+
+// defaultValue returns value, or def when value is missing or empty
+func defaultValue(def, value interface{}) interface{} {
+	if value == nil {
+		return def
+	}
+	if s, ok := value.(string); ok && s == "" {
+		return def
+	}
+	return value
+}
+
+// split splits s around sep, e.g. the values of a multiple checkbox property
+func split(sep string, value interface{}) []string {
+	if value == nil || value == "" {
+		return nil
+	}
+	return strings.Split(fmt.Sprint(value), sep)
+}
+
+// join joins the elements of a slice with sep. A string is returned as is.
+func join(sep string, value interface{}) (string, error) {
+	if value == nil {
+		return "", nil
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+
+	v := reflect.ValueOf(value)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: cannot join %T", value)
+	}
+	parts := make([]string, v.Len())
+	for i := range parts {
+		parts[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+	return strings.Join(parts, sep), nil
+}
@@ -0,0 +1,52 @@
+package output
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTemplate(t *testing.T) {
+	object := map[string]interface{}{
+		"ID": "1001",
+		"Properties": map[string]interface{}{
+			"email":      "ada@example.com",
+			"createdate": "2024-03-05T10:15:00.000Z",
+			"closedate":  "1709633700000",
+			"tags":       "red;green",
+		},
+	}
+
+	tests := []struct {
+		text string
+		want string
+	}{
+		{`{{.ID}} {{index .Properties "email"}}`, "1001 ada@example.com"},
+		{`{{index .Properties "createdate" | date "2006-01-02"}}`, "2024-03-05"},
+		{`{{index .Properties "closedate" | date "2006-01-02 15:04"}}`, "2024-03-05 10:15"},
+		{`{{index .Properties "missing" | date "2006-01-02"}}`, ""},
+		{`{{index .Properties "phone" | default "n/a"}}`, "n/a"},
+		{`{{index .Properties "email" | default "n/a"}}`, "ada@example.com"},
+		{`{{index .Properties "tags" | split ";" | join ", "}}`, "red, green"},
+		{`{{index .Properties "missing" | split ";" | join ", "}}`, ""},
+		{`{{index .Properties "email" | upper}}`, "ADA@EXAMPLE.COM"},
+	}
+	for _, tt := range tests {
+		tmpl, err := ParseTemplate(tt.text)
+		if err != nil {
+			t.Fatalf("ParseTemplate(%s) failed: %v", tt.text, err)
+		}
+		var out strings.Builder
+		if err := tmpl.Execute(&out, object); err != nil {
+			t.Fatalf("Execute(%s) failed: %v", tt.text, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: expected %q, got %q", tt.text, tt.want, out.String())
+		}
+	}
+}
+
+func TestParseTemplate_Invalid(t *testing.T) {
+	if _, err := ParseTemplate("{{.ID"); err == nil {
+		t.Error("Expected error for an unterminated action")
+	}
+}
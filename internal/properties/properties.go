@@ -0,0 +1,107 @@
+// Package properties resolves the property lists read commands request:
+// @name property sets defined in the config file and the default properties
+// configured per object type.
+package properties
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Sets maps lowercased property set names to their properties
+type Sets map[string][]string
+
+// Expand splits a comma-separated property list, replacing @name entries
+// with the matching property set and dropping duplicates
+func (s Sets) Expand(spec string) ([]string, error) {
+	var names []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if !strings.HasPrefix(entry, "@") {
+			add(entry)
+			continue
+		}
+
+		set, ok := s[strings.ToLower(entry[1:])]
+		if !ok {
+			return nil, fmt.Errorf("unknown property set %q; define it under property-sets in the config file", entry[1:])
+		}
+		for _, name := range set {
+			add(name)
+		}
+	}
+
+	if len(names) == 0 {
+		return nil, fmt.Errorf("no properties selected")
+	}
+	return names, nil
+}
+
+// Resolve returns the property spec a read command should expand: spec when
+// given, otherwise the defaults configured for the first of keys that has
+// any. Keys are tried in order, so a command name such as line-items can
+// take precedence over the object type it reads. An empty result means the
+// built-in defaults.
+func Resolve(spec string, defaults func(key string) []string, keys ...string) string {
+	if spec != "" {
+		return spec
+	}
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if names := defaults(key); len(names) > 0 {
+			return strings.Join(names, ",")
+		}
+	}
+	return ""
+}
+
+// ParseDefaults reads default properties given per object type as
+// type=prop1,prop2, returning the properties of each type as a list
+func ParseDefaults(entries []string) (map[string][]string, error) {
+	byType := make(map[string][]string)
+	for _, entry := range entries {
+		objectType, spec, _ := strings.Cut(entry, "=")
+		objectType = strings.TrimSpace(objectType)
+		names := Split(spec)
+		if objectType == "" || len(names) == 0 {
+			return nil, fmt.Errorf("invalid default properties %q, expected object-type=prop1,prop2", entry)
+		}
+		byType[objectType] = names
+	}
+	return byType, nil
+}
+
+// Split reads a property list from the config file, either a list or a
+// comma-separated string
+func Split(value interface{}) []string {
+	var entries []string
+	switch v := value.(type) {
+	case string:
+		entries = strings.Split(v, ",")
+	case []string:
+		entries = v
+	case []interface{}:
+		for _, entry := range v {
+			entries = append(entries, fmt.Sprint(entry))
+		}
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			names = append(names, entry)
+		}
+	}
+	return names
+}
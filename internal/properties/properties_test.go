@@ -0,0 +1,154 @@
+package properties
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestSets_Expand(t *testing.T) {
+	sets := Sets{
+		"sales":     {"email", "phone", "hubspot_owner_id"},
+		"marketing": {"email", "hs_analytics_source"},
+	}
+
+	tests := []struct {
+		name string
+		spec string
+		want []string
+		err  string
+	}{
+		{name: "plain list", spec: "email, phone ,,email", want: []string{"email", "phone"}},
+		{name: "set", spec: "@sales,lastname", want: []string{"email", "phone", "hubspot_owner_id", "lastname"}},
+		{name: "set name ignores case", spec: "@Sales", want: []string{"email", "phone", "hubspot_owner_id"}},
+		{name: "set used twice", spec: "@sales,email,@SALES", want: []string{"email", "phone", "hubspot_owner_id"}},
+		{name: "overlapping sets", spec: "@marketing,@sales", want: []string{"email", "hs_analytics_source", "phone", "hubspot_owner_id"}},
+		{name: "unknown set", spec: "email,@missing", err: `unknown property set "missing"`},
+		{name: "empty", spec: " , ", err: "no properties selected"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := sets.Expand(tt.spec)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Expected error containing %q, got %v (%v)", tt.err, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expand failed: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestResolve(t *testing.T) {
+	config := map[string][]string{
+		"line-items": {"name", "quantity"},
+		"line_items": {"name"},
+		"contacts":   {"email", "@sales"},
+	}
+	defaults := func(key string) []string { return config[key] }
+
+	tests := []struct {
+		name string
+		spec string
+		keys []string
+		want string
+	}{
+		{name: "explicit spec wins", spec: "email", keys: []string{"contacts"}, want: "email"},
+		{name: "configured default", keys: []string{"contacts", "contacts"}, want: "email,@sales"},
+		{name: "command name before object type", keys: []string{"line-items", "line_items"}, want: "name,quantity"},
+		{name: "object type when the command has none", keys: []string{"items", "line_items"}, want: "name"},
+		{name: "empty key skipped", keys: []string{"", "contacts"}, want: "email,@sales"},
+		{name: "built-in defaults", keys: []string{"deals", "deals"}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Resolve(tt.spec, defaults, tt.keys...); got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	got, err := ParseDefaults([]string{"contacts=email,firstname, lastname", " deals = dealname "})
+	if err != nil {
+		t.Fatalf("ParseDefaults failed: %v", err)
+	}
+	want := map[string][]string{"contacts": {"email", "firstname", "lastname"}, "deals": {"dealname"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	for _, entry := range []string{"contacts", "=email", "contacts= , "} {
+		if _, err := ParseDefaults([]string{entry}); err == nil {
+			t.Errorf("Expected error for %q", entry)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  []string
+	}{
+		{"email,firstname, lastname", []string{"email", "firstname", "lastname"}},
+		{[]interface{}{"email", " @sales "}, []string{"email", "@sales"}},
+		{[]string{"email", ""}, []string{"email"}},
+		{nil, nil},
+	}
+	for _, tt := range tests {
+		if got := Split(tt.value); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Split(%#v) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestDefaults_ConfigRoundTrip writes default properties to a config file
+// and reads them back through a profile, as profile add and read commands do
+func TestDefaults_ConfigRoundTrip(t *testing.T) {
+	byType, err := ParseDefaults([]string{"contacts=email,firstname, lastname,@sales"})
+	if err != nil {
+		t.Fatalf("ParseDefaults failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := viper.New()
+	file.SetConfigType("yaml")
+	file.Set("property-sets", map[string][]string{"sales": {"phone", "email"}})
+	file.Set("profiles", map[string]interface{}{"work": map[string]interface{}{"default-properties": byType}})
+	if err := file.WriteConfigAs(path); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	config := viper.New()
+	config.SetConfigFile(path)
+	if err := config.ReadInConfig(); err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if err := config.MergeConfigMap(config.GetStringMap("profiles.work")); err != nil {
+		t.Fatalf("Failed to apply profile: %v", err)
+	}
+
+	if stored, ok := config.Get("default-properties.contacts").([]interface{}); !ok || len(stored) != 4 {
+		t.Errorf("Expected the default properties to be stored as a list, got %#v", config.Get("default-properties.contacts"))
+	}
+
+	defaults := func(key string) []string { return Split(config.Get("default-properties." + key)) }
+	spec := Resolve("", defaults, "contacts")
+	got, err := Sets(config.GetStringMapStringSlice("property-sets")).Expand(spec)
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if want := []string{"email", "firstname", "lastname", "phone"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,176 @@
+package query
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Aggregate computes a value over the objects of a group, e.g. sum:amount
+type Aggregate struct {
+	Func     string
+	Property string
+}
+
+// aggregateFuncs lists the aggregation functions and whether they need a property
+var aggregateFuncs = map[string]bool{
+	"count":    false,
+	"distinct": true,
+	"sum":      true,
+	"avg":      true,
+	"min":      true,
+	"max":      true,
+}
+
+// Name labels the aggregate's column, e.g. count or sum(amount)
+func (a Aggregate) Name() string {
+	if a.Property == "" {
+		return a.Func
+	}
+	return fmt.Sprintf("%s(%s)", a.Func, a.Property)
+}
+
+// ParseAggregates parses specs of the form func[:property], where func is
+// count, distinct, sum, avg, min or max. count counts the objects, or those
+// with a value for the property; the others need a property.
+func ParseAggregates(specs []string) ([]Aggregate, error) {
+	var aggregates []Aggregate
+	for _, spec := range specs {
+		fn, property, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		a := Aggregate{Func: strings.ToLower(strings.TrimSpace(fn)), Property: strings.TrimSpace(property)}
+
+		needsProperty, ok := aggregateFuncs[a.Func]
+		if !ok {
+			return nil, fmt.Errorf("invalid aggregate %q, expected count, distinct, sum, avg, min or max", spec)
+		}
+		if needsProperty && a.Property == "" {
+			return nil, fmt.Errorf("invalid aggregate %q, expected %s:property", spec, a.Func)
+		}
+		aggregates = append(aggregates, a)
+	}
+	return aggregates, nil
+}
+
+// Group is a row of aggregated results
+type Group struct {
+	// Keys are the group's values of the group-by properties
+	Keys []string
+	// Count is the number of objects in the group
+	Count int
+	// Values are the results of the aggregates: an int for count and
+	// distinct, a float64 for sum and avg, and a string for min and max.
+	// Averages, minimums and maximums are nil when no object has a value.
+	Values []interface{}
+}
+
+// Summarize groups rows by their values of groupBy and computes the
+// aggregates of each group. Sums and averages skip values that are not
+// numbers, and min and max order values like search filters do. Groups are
+// ordered by descending size, then by their keys.
+func Summarize(rows []Getter, groupBy []string, aggregates []Aggregate) []Group {
+	type members struct {
+		group *Group
+		rows  []Getter
+	}
+	var order []*members
+	byKey := make(map[string]*members)
+
+	for _, get := range rows {
+		keys := make([]string, len(groupBy))
+		for i, name := range groupBy {
+			keys[i] = get(name)
+		}
+		id := strings.Join(keys, "\x00")
+		m, ok := byKey[id]
+		if !ok {
+			m = &members{group: &Group{Keys: keys}}
+			byKey[id] = m
+			order = append(order, m)
+		}
+		m.group.Count++
+		m.rows = append(m.rows, get)
+	}
+
+	result := make([]Group, len(order))
+	for i, m := range order {
+		for _, a := range aggregates {
+			m.group.Values = append(m.group.Values, compute(a, m.rows))
+		}
+		result[i] = *m.group
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		for k := range result[i].Keys {
+			if c := Compare(result[i].Keys[k], result[j].Keys[k]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return result
+}
+
+func compute(a Aggregate, rows []Getter) interface{} {
+	switch a.Func {
+	case "count":
+		if a.Property == "" {
+			return len(rows)
+		}
+		n := 0
+		for _, get := range rows {
+			if get(a.Property) != "" {
+				n++
+			}
+		}
+		return n
+
+	case "distinct":
+		seen := make(map[string]bool)
+		for _, get := range rows {
+			if value := get(a.Property); value != "" {
+				seen[strings.ToLower(value)] = true
+			}
+		}
+		return len(seen)
+
+	case "sum", "avg":
+		sum, n := 0.0, 0
+		for _, get := range rows {
+			if f, err := strconv.ParseFloat(get(a.Property), 64); err == nil {
+				sum += f
+				n++
+			}
+		}
+		if a.Func == "sum" {
+			// Drop the noise of adding decimal fractions in binary
+			return math.Round(sum*1e9) / 1e9
+		}
+		if n == 0 {
+			return nil
+		}
+		return math.Round(sum/float64(n)*100) / 100
+
+	case "min", "max":
+		var best string
+		for _, get := range rows {
+			value := get(a.Property)
+			if value == "" {
+				continue
+			}
+			c := Compare(value, best)
+			if best == "" || a.Func == "min" && c < 0 || a.Func == "max" && c > 0 {
+				best = value
+			}
+		}
+		if best == "" {
+			return nil
+		}
+		return best
+	}
+	return nil
+}
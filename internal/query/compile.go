@@ -0,0 +1,171 @@
+// Package query compiles boolean filter expressions such as
+//
+//	lifecyclestage = lead AND createdate >= 2025-01-01 OR (company ~ acme AND NOT email HAS_PROPERTY)
+//
+// into HubSpot search filter groups. AND binds tighter than OR, NOT negates
+// the expression that follows it and parentheses group. Because HubSpot only
+// accepts an OR of ANDs, the expression is rewritten into that form, pushing
+// NOT down to the filters by negating their operators.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// maxExpansion stops the rewrite before pathological queries exhaust memory
+const maxExpansion = 1000
+
+// Compile parses a query and compiles it into filter groups, checking them
+// against HubSpot's limits on filter groups and filters
+func Compile(input string) ([]hubspot.FilterGroup, error) {
+	n, err := parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	dnf, err := normalize(n, false)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]hubspot.FilterGroup, len(dnf))
+	for i, filters := range dnf {
+		for _, f := range filters {
+			if f.Operator == "MATCHES" || f.Operator == "NOT_MATCHES" {
+				return nil, fmt.Errorf("HubSpot search does not support regular expressions; MATCHES only works on the local mirror")
+			}
+		}
+		groups[i] = hubspot.FilterGroup{Filters: filters}
+	}
+	if err := Validate(groups); err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// Validate checks filter groups against HubSpot's search limits
+func Validate(groups []hubspot.FilterGroup) error {
+	if len(groups) > hubspot.MaxFilterGroups {
+		return fmt.Errorf("query needs %d filter groups (OR branches) but HubSpot allows at most %d", len(groups), hubspot.MaxFilterGroups)
+	}
+
+	total := 0
+	for i, group := range groups {
+		if len(group.Filters) > hubspot.MaxFiltersPerGroup {
+			return fmt.Errorf("filter group %d has %d filters but HubSpot allows at most %d per group", i+1, len(group.Filters), hubspot.MaxFiltersPerGroup)
+		}
+		total += len(group.Filters)
+	}
+	if total > hubspot.MaxFilters {
+		return fmt.Errorf("query needs %d filters but HubSpot allows at most %d in total", total, hubspot.MaxFilters)
+	}
+	return nil
+}
+
+// normalize rewrites an expression, negated if negate is set, into a
+// disjunction of conjunctions of filters
+func normalize(n node, negate bool) ([][]hubspot.Filter, error) {
+	switch n := n.(type) {
+	case comparison:
+		if negate {
+			return negateFilter(n.filter), nil
+		}
+		return [][]hubspot.Filter{{n.filter}}, nil
+
+	case negation:
+		return normalize(n.x, !negate)
+
+	case binary:
+		left, err := normalize(n.left, negate)
+		if err != nil {
+			return nil, err
+		}
+		right, err := normalize(n.right, negate)
+		if err != nil {
+			return nil, err
+		}
+
+		// De Morgan: a negated AND is an OR and vice versa
+		if n.and != negate {
+			if len(left)*len(right) > maxExpansion {
+				return nil, fmt.Errorf("query is too complex to rewrite into HubSpot filter groups")
+			}
+			var product [][]hubspot.Filter
+			for _, l := range left {
+				for _, r := range right {
+					group := make([]hubspot.Filter, 0, len(l)+len(r))
+					group = append(append(group, l...), r...)
+					product = append(product, group)
+				}
+			}
+			return product, nil
+		}
+		return append(left, right...), nil
+	}
+	return nil, fmt.Errorf("unexpected expression %T", n)
+}
+
+// negatedOperators maps each operator to its negation
+var negatedOperators = map[string]string{
+	"EQ":                 "NEQ",
+	"NEQ":                "EQ",
+	"LT":                 "GTE",
+	"GTE":                "LT",
+	"LTE":                "GT",
+	"GT":                 "LTE",
+	"IN":                 "NOT_IN",
+	"NOT_IN":             "IN",
+	"HAS_PROPERTY":       "NOT_HAS_PROPERTY",
+	"NOT_HAS_PROPERTY":   "HAS_PROPERTY",
+	"CONTAINS_TOKEN":     "NOT_CONTAINS_TOKEN",
+	"NOT_CONTAINS_TOKEN": "CONTAINS_TOKEN",
+	"MATCHES":            "NOT_MATCHES",
+	"NOT_MATCHES":        "MATCHES",
+}
+
+// negateFilter returns the filter groups matching what f does not match
+func negateFilter(f hubspot.Filter) [][]hubspot.Filter {
+	if f.Operator == "BETWEEN" {
+		return [][]hubspot.Filter{
+			{{PropertyName: f.PropertyName, Operator: "LT", Value: f.Value}},
+			{{PropertyName: f.PropertyName, Operator: "GT", Value: f.HighValue}},
+		}
+	}
+	f.Operator = negatedOperators[f.Operator]
+	return [][]hubspot.Filter{{f}}
+}
+
+var (
+	datePattern     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	dateTimePattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T`)
+)
+
+// normalizeValue converts unquoted dates (2025-01-01) and timestamps
+// (RFC 3339) to the Unix milliseconds HubSpot expects for date properties.
+// Token searches and regular expressions keep their value as typed.
+func normalizeValue(operator, value string) string {
+	switch operator {
+	case "CONTAINS_TOKEN", "NOT_CONTAINS_TOKEN", "MATCHES", "NOT_MATCHES":
+		return value
+	}
+
+	var t time.Time
+	var err error
+	switch {
+	case datePattern.MatchString(value):
+		t, err = time.Parse("2006-01-02", value)
+	case dateTimePattern.MatchString(value):
+		t, err = time.Parse(time.RFC3339, value)
+	default:
+		return value
+	}
+	if err != nil {
+		return value
+	}
+	return strconv.FormatInt(t.UnixMilli(), 10)
+}
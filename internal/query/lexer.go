@@ -0,0 +1,169 @@
+package query
+
+import (
+	"strings"
+	"unicode"
+)
+
+// tokenKind classifies lexer tokens
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+// token is a lexeme with its 1-based column in the input
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// symbolOperators maps operator symbols to HubSpot operators, longest first
+var symbolOperators = []struct {
+	symbol   string
+	operator string
+}{
+	{"==", "EQ"},
+	{"!=", "NEQ"},
+	{"<>", "NEQ"},
+	{"<=", "LTE"},
+	{">=", "GTE"},
+	{"!~", "NOT_CONTAINS_TOKEN"},
+	{"=", "EQ"},
+	{"<", "LT"},
+	{">", "GT"},
+	{"~", "CONTAINS_TOKEN"},
+}
+
+// lex splits input into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{tokenLParen, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{tokenRParen, ")", pos})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{tokenComma, ",", pos})
+			i++
+		case r == '"' || r == '\'':
+			var b strings.Builder
+			closed := false
+			j := i + 1
+			for ; j < len(runes); j++ {
+				// Escapes are kept for the parser, which resolves them
+				// unless the string is a regular expression
+				if runes[j] == '\\' && j+1 < len(runes) {
+					b.WriteRune(runes[j])
+					j++
+					b.WriteRune(runes[j])
+					continue
+				}
+				if runes[j] == r {
+					closed = true
+					break
+				}
+				b.WriteRune(runes[j])
+			}
+			if !closed {
+				return nil, &SyntaxError{Pos: pos, Msg: "unterminated string"}
+			}
+			tokens = append(tokens, token{tokenString, b.String(), pos})
+			i = j + 1
+		case strings.ContainsRune("=!<>~", r):
+			rest := string(runes[i:])
+			matched := false
+			for _, op := range symbolOperators {
+				if strings.HasPrefix(rest, op.symbol) {
+					tokens = append(tokens, token{tokenOperator, op.symbol, pos})
+					i += len([]rune(op.symbol))
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, &SyntaxError{Pos: pos, Msg: "unexpected " + quote(string(r))}
+			}
+		default:
+			// Quotes and operator characters may appear inside a word, e.g.
+			// O'Brien. The value after an operator runs to the next space or
+			// ')', so that it can also contain '=', e.g. a URL's query string.
+			stop := `(),=!<>~`
+			if len(tokens) > 0 && tokens[len(tokens)-1].kind == tokenOperator {
+				stop = ")"
+			}
+			j := i + 1
+			for j < len(runes) && !unicode.IsSpace(runes[j]) && !strings.ContainsRune(stop, runes[j]) {
+				j++
+			}
+			tokens = append(tokens, token{tokenWord, string(runes[i:j]), pos})
+			i = j
+		}
+	}
+
+	return append(tokens, token{tokenEOF, "", len(runes) + 1}), nil
+}
+
+// IsExpression reports whether input uses the expression syntax, as opposed
+// to a bare search term such as "jane", "acme corp" or a lone quoted phrase.
+// Input that fails to lex is an expression, so that Compile reports the
+// syntax error.
+func IsExpression(input string) bool {
+	tokens, err := lex(input)
+	if err != nil {
+		return true
+	}
+	if len(tokens) == 2 && tokens[0].kind == tokenString {
+		return false
+	}
+	for _, t := range tokens {
+		switch t.kind {
+		case tokenEOF:
+		case tokenWord:
+			if isKeyword(t.text) {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func quote(s string) string {
+	return "'" + s + "'"
+}
+
+// unescape resolves the backslash escapes of a quoted string: a backslash
+// makes the next character literal
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var b strings.Builder
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == '\\' && i+1 < len(runes) {
+			i++
+		}
+		b.WriteRune(runes[i])
+	}
+	return b.String()
+}
@@ -0,0 +1,337 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// Getter returns the value of a property, or an empty string when the
+// object has none
+type Getter func(property string) string
+
+// ObjectGetter returns a Getter for the properties of an object
+func ObjectGetter(object hubspot.Object) Getter {
+	return func(property string) string {
+		switch value := object.Properties[property].(type) {
+		case nil:
+			return ""
+		case string:
+			return value
+		default:
+			return fmt.Sprint(value)
+		}
+	}
+}
+
+// SearchableProperties are the default searchable properties HubSpot
+// matches a free-text query against
+var SearchableProperties = map[string][]string{
+	hubspot.Contacts.Name:  {"firstname", "lastname", "email", "phone", "mobilephone", "company", "website"},
+	hubspot.Companies.Name: {"name", "domain", "website", "phone"},
+	hubspot.Deals.Name:     {"dealname"},
+	hubspot.Tickets.Name:   {"subject", "content"},
+	hubspot.Products.Name:  {"name", "description", "hs_sku"},
+	hubspot.LineItems.Name: {"name"},
+}
+
+// Expr is a parsed query that is evaluated locally, e.g. against a mirror.
+// It follows the semantics of HubSpot's search, but is not bound by its
+// limits and also supports the MATCHES regular expression operator.
+type Expr struct {
+	root    node
+	regexps map[string]*regexp.Regexp
+}
+
+// Parse parses a query for local evaluation
+func Parse(input string) (*Expr, error) {
+	n, err := parse(input)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Expr{root: n, regexps: make(map[string]*regexp.Regexp)}
+	for _, f := range filters(n) {
+		if f.Operator != "MATCHES" && f.Operator != "NOT_MATCHES" {
+			continue
+		}
+		re, err := regexp.Compile(f.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression for %s: %w", f.PropertyName, err)
+		}
+		e.regexps[f.Value] = re
+	}
+	return e, nil
+}
+
+// Properties returns the properties the query refers to
+func (e *Expr) Properties() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, f := range filters(e.root) {
+		if !seen[f.PropertyName] {
+			seen[f.PropertyName] = true
+			names = append(names, f.PropertyName)
+		}
+	}
+	return names
+}
+
+// Match reports whether an object matches the query
+func (e *Expr) Match(get Getter) bool {
+	return e.eval(e.root, false, get)
+}
+
+// eval evaluates an expression, negated if negate is set. Negations are
+// pushed down to the filters like Compile does, so that a negated
+// comparison treats missing values the way HubSpot does.
+func (e *Expr) eval(n node, negate bool, get Getter) bool {
+	switch n := n.(type) {
+	case comparison:
+		if !negate {
+			return e.matchFilter(n.filter, get(n.filter.PropertyName))
+		}
+		for _, group := range negateFilter(n.filter) {
+			if e.matchFilter(group[0], get(n.filter.PropertyName)) {
+				return true
+			}
+		}
+		return false
+
+	case negation:
+		return e.eval(n.x, !negate, get)
+
+	case binary:
+		if n.and != negate {
+			return e.eval(n.left, negate, get) && e.eval(n.right, negate, get)
+		}
+		return e.eval(n.left, negate, get) || e.eval(n.right, negate, get)
+	}
+	return false
+}
+
+func (e *Expr) matchFilter(f hubspot.Filter, value string) bool {
+	switch f.Operator {
+	case "MATCHES":
+		return value != "" && e.regexps[f.Value].MatchString(value)
+	case "NOT_MATCHES":
+		return value == "" || !e.regexps[f.Value].MatchString(value)
+	}
+	return MatchFilter(f, value)
+}
+
+// filters returns the comparisons of an expression
+func filters(n node) []hubspot.Filter {
+	switch n := n.(type) {
+	case comparison:
+		return []hubspot.Filter{n.filter}
+	case negation:
+		return filters(n.x)
+	case binary:
+		return append(filters(n.left), filters(n.right)...)
+	}
+	return nil
+}
+
+// MatchGroups reports whether an object matches any of the filter groups,
+// whose filters are ANDed. No groups match everything.
+func MatchGroups(groups []hubspot.FilterGroup, get Getter) bool {
+	if len(groups) == 0 {
+		return true
+	}
+	for _, group := range groups {
+		matched := true
+		for _, f := range group.Filters {
+			if !MatchFilter(f, get(f.PropertyName)) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchFilter reports whether a property value matches a search filter the
+// way HubSpot evaluates it. An empty value means the property is not set.
+func MatchFilter(f hubspot.Filter, value string) bool {
+	ok := value != ""
+
+	switch f.Operator {
+	case "HAS_PROPERTY":
+		return ok
+	case "NOT_HAS_PROPERTY":
+		return !ok
+	case "NEQ":
+		return !ok || Compare(value, f.Value) != 0
+	case "NOT_IN":
+		return !ok || !containsFold(f.Values, value)
+	case "NOT_CONTAINS_TOKEN":
+		return !ok || !containsToken(value, f.Value)
+	}
+
+	if !ok {
+		return false
+	}
+	switch f.Operator {
+	case "EQ":
+		return Compare(value, f.Value) == 0
+	case "LT":
+		return Compare(value, f.Value) < 0
+	case "LTE":
+		return Compare(value, f.Value) <= 0
+	case "GT":
+		return Compare(value, f.Value) > 0
+	case "GTE":
+		return Compare(value, f.Value) >= 0
+	case "BETWEEN":
+		return Compare(value, f.Value) >= 0 && Compare(value, f.HighValue) <= 0
+	case "IN":
+		return containsFold(f.Values, value)
+	case "CONTAINS_TOKEN":
+		return containsToken(value, f.Value)
+	}
+	return false
+}
+
+// MatchText reports whether a word of one of the values starts with every
+// word of the query, like HubSpot's free-text search
+func MatchText(values []string, text string) bool {
+	for _, word := range strings.Fields(strings.ToLower(text)) {
+		found := false
+		for _, value := range values {
+			value = strings.ToLower(value)
+			if strings.HasPrefix(value, word) {
+				found = true
+				break
+			}
+			for _, token := range strings.FieldsFunc(value, func(r rune) bool { return r < 0x80 && !isWordChar(byte(r)) }) {
+				if strings.HasPrefix(token, word) {
+					found = true
+					break
+				}
+			}
+			if found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// Less reports whether the object of a sorts before the object of b. Objects
+// without a value sort last.
+func Less(sorts []hubspot.Sort, a, b Getter) bool {
+	for _, s := range sorts {
+		va, vb := a(s.PropertyName), b(s.PropertyName)
+		if (va != "") != (vb != "") {
+			return va != ""
+		}
+		c := Compare(va, vb)
+		if c == 0 {
+			continue
+		}
+		if s.Direction == hubspot.SortDescending {
+			return c > 0
+		}
+		return c < 0
+	}
+	return false
+}
+
+// Compare orders two values numerically when both are numbers and
+// case-insensitively otherwise. Dates are compared as Unix milliseconds,
+// which is how HubSpot expects them in filters.
+func Compare(a, b string) int {
+	a, b = dateMillis(a), dateMillis(b)
+	fa, errA := strconv.ParseFloat(a, 64)
+	fb, errB := strconv.ParseFloat(b, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+}
+
+// dateMillis converts an ISO 8601 date or timestamp to Unix milliseconds
+// and returns other values unchanged
+func dateMillis(value string) string {
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return strconv.FormatInt(t.UnixMilli(), 10)
+		}
+	}
+	return value
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsToken matches token against whole words of value, case-insensitively.
+// A * in token matches any run of characters.
+func containsToken(value, token string) bool {
+	value, token = strings.ToLower(value), strings.ToLower(strings.TrimSpace(token))
+	if token == "" {
+		return false
+	}
+	if strings.Contains(token, "*") {
+		return wildcard(value, token)
+	}
+
+	for start := 0; ; {
+		i := strings.Index(value[start:], token)
+		if i < 0 {
+			return false
+		}
+		i += start
+		end := i + len(token)
+		if (i == 0 || !isWordChar(value[i-1])) && (end == len(value) || !isWordChar(value[end])) {
+			return true
+		}
+		start = i + 1
+	}
+}
+
+// wildcard matches value against a pattern where * matches any run of characters
+func wildcard(value, pattern string) bool {
+	parts := strings.Split(pattern, "*")
+	if !strings.HasPrefix(value, parts[0]) {
+		return false
+	}
+	value = value[len(parts[0]):]
+	for i, part := range parts[1:] {
+		if i == len(parts)-2 {
+			return strings.HasSuffix(value, part)
+		}
+		j := strings.Index(value, part)
+		if j < 0 {
+			return false
+		}
+		value = value[j+len(part):]
+	}
+	return value == ""
+}
+
+func isWordChar(b byte) bool {
+	return b >= 'a' && b <= 'z' || b >= '0' && b <= '9' || b >= 0x80
+}
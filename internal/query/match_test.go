@@ -0,0 +1,143 @@
+package query
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func getter(properties map[string]string) Getter {
+	return func(name string) string { return properties[name] }
+}
+
+func TestExpr_Match(t *testing.T) {
+	ada := getter(map[string]string{
+		"email": "ada@gmail.com", "firstname": "Ada", "company": "Analytical Engines",
+		"amount": "250", "createdate": "2025-03-01T10:00:00Z",
+	})
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{"firstname = ada", true},
+		{"amount > 100 AND amount < 300", true},
+		{"createdate >= 2025-01-01", true},
+		{"company ~ engines", true},
+		{"company ~ engine", false},
+		{"phone HAS_PROPERTY", false},
+		{"NOT phone HAS_PROPERTY", true},
+		{"phone != 123", true},
+		// A negated comparison on a missing property matches, like HubSpot's NEQ
+		{"NOT phone = 123", true},
+		{"NOT (firstname = ada OR amount > 1000)", false},
+		{`email MATCHES '@(gmail|yahoo)\.com$'`, true},
+		{`email MATCHES '^\d+'`, false},
+		{`email NOT MATCHES 'gmail'`, false},
+		{`phone NOT MATCHES '.'`, true},
+		{`phone MATCHES '.*'`, false},
+		{"firstname IN (grace, ADA)", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			expr, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if got := expr.Match(ada); got != tt.want {
+				t.Errorf("Match = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	if _, err := Parse("email MATCHES '('"); err == nil || !strings.Contains(err.Error(), "invalid regular expression") {
+		t.Errorf("Expected an invalid regular expression error, got %v", err)
+	}
+	if _, err := Compile("email MATCHES 'gmail'"); err == nil || !strings.Contains(err.Error(), "local mirror") {
+		t.Errorf("Expected Compile to reject MATCHES, got %v", err)
+	}
+}
+
+func TestExpr_Properties(t *testing.T) {
+	expr, err := Parse("a = 1 AND (b ~ x OR NOT a IN (2, 3)) AND c MATCHES 'y'")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := expr.Properties(), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+}
+
+func TestParseAggregates(t *testing.T) {
+	aggregates, err := ParseAggregates([]string{"count", "SUM:amount", "distinct: company"})
+	if err != nil {
+		t.Fatalf("ParseAggregates failed: %v", err)
+	}
+	var names []string
+	for _, a := range aggregates {
+		names = append(names, a.Name())
+	}
+	if want := []string{"count", "sum(amount)", "distinct(company)"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Expected %v, got %v", want, names)
+	}
+
+	for _, spec := range []string{"median:amount", "sum", "avg:"} {
+		if _, err := ParseAggregates([]string{spec}); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	rows := []Getter{
+		getter(map[string]string{"stage": "lead", "amount": "10.1", "company": "Acme"}),
+		getter(map[string]string{"stage": "customer", "amount": "500", "company": "Initech"}),
+		getter(map[string]string{"stage": "lead", "amount": "20.2", "company": "acme"}),
+		getter(map[string]string{"stage": "lead", "company": "Globex"}),
+		getter(map[string]string{"amount": "7"}),
+	}
+	aggregates, err := ParseAggregates([]string{"count", "count:amount", "distinct:company", "sum:amount", "avg:amount", "max:company"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	groups := Summarize(rows, []string{"stage"}, aggregates)
+	want := []Group{
+		{Keys: []string{"lead"}, Count: 3, Values: []interface{}{3, 2, 2, 30.3, 15.15, "Globex"}},
+		{Keys: []string{""}, Count: 1, Values: []interface{}{1, 1, 0, 7.0, 7.0, nil}},
+		{Keys: []string{"customer"}, Count: 1, Values: []interface{}{1, 1, 1, 500.0, 500.0, "Initech"}},
+	}
+	if !reflect.DeepEqual(groups, want) {
+		t.Errorf("Summarize\n got %+v\nwant %+v", groups, want)
+	}
+
+	if groups := Summarize(rows, nil, []Aggregate{{Func: "count"}}); len(groups) != 1 || groups[0].Values[0] != 5 {
+		t.Errorf("Expected one group of 5 without group-by, got %+v", groups)
+	}
+}
+
+func TestParse_RegexpEscapes(t *testing.T) {
+	tests := []struct {
+		input, value string
+		want         bool
+	}{
+		{`name MATCHES 'it\'s \d'`, "it's 1", true},
+		{`name MATCHES "^\d+$"`, "42", true},
+		{`name MATCHES "^\d+$"`, "d", false},
+		{`name MATCHES 'C:\\temp'`, `C:\temp`, true},
+		// Other operators resolve the escapes
+		{`name = "\d"`, "d", true},
+		{`name NOT MATCHES '\.'`, "ab", true},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.input)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", tt.input, err)
+		}
+		if got := expr.Match(getter(map[string]string{"name": tt.value})); got != tt.want {
+			t.Errorf("%s on %q = %v, want %v", tt.input, tt.value, got, tt.want)
+		}
+	}
+}
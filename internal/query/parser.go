@@ -0,0 +1,301 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// SyntaxError describes a parse error at a 1-based column of the input
+type SyntaxError struct {
+	Pos int
+	Msg string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("syntax error at column %d: %s", e.Pos, e.Msg)
+}
+
+// node is an expression of the parsed query
+type node interface{}
+
+// binary combines two expressions with AND or OR
+type binary struct {
+	and         bool
+	left, right node
+}
+
+// negation negates an expression
+type negation struct {
+	x node
+}
+
+// comparison is a single property filter
+type comparison struct {
+	filter hubspot.Filter
+}
+
+// keywordOperators maps operator keywords to HubSpot operators and the
+// number of values they take (-1 for a parenthesized list)
+var keywordOperators = map[string]struct {
+	operator string
+	values   int
+}{
+	"EQ":                 {"EQ", 1},
+	"NEQ":                {"NEQ", 1},
+	"LT":                 {"LT", 1},
+	"LTE":                {"LTE", 1},
+	"GT":                 {"GT", 1},
+	"GTE":                {"GTE", 1},
+	"CONTAINS":           {"CONTAINS_TOKEN", 1},
+	"CONTAINS_TOKEN":     {"CONTAINS_TOKEN", 1},
+	"NOT_CONTAINS_TOKEN": {"NOT_CONTAINS_TOKEN", 1},
+	"BETWEEN":            {"BETWEEN", 2},
+	"IN":                 {"IN", -1},
+	"NOT_IN":             {"NOT_IN", -1},
+	"HAS_PROPERTY":       {"HAS_PROPERTY", 0},
+	"NOT_HAS_PROPERTY":   {"NOT_HAS_PROPERTY", 0},
+	// Regular expressions are only evaluated locally
+	"MATCHES":     {"MATCHES", 1},
+	"NOT_MATCHES": {"NOT_MATCHES", 1},
+}
+
+// isKeyword reports whether a word is reserved by the query language
+func isKeyword(word string) bool {
+	upper := strings.ToUpper(word)
+	if upper == "AND" || upper == "OR" || upper == "NOT" {
+		return true
+	}
+	_, ok := keywordOperators[upper]
+	return ok
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+// parse parses a query into an expression tree
+func parse(input string) (node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	if p.peek().kind == tokenEOF {
+		return nil, &SyntaxError{Pos: 1, Msg: "empty query"}
+	}
+	n, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, p.unexpected(t, "AND, OR or end of query")
+	}
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the given keyword
+func (p *parser) keyword(word string) bool {
+	t := p.peek()
+	return t.kind == tokenWord && strings.EqualFold(t.text, word)
+}
+
+func (p *parser) unexpected(t token, expected string) error {
+	if t.kind == tokenEOF {
+		return &SyntaxError{Pos: t.pos, Msg: "unexpected end of query, expected " + expected}
+	}
+	return &SyntaxError{Pos: t.pos, Msg: fmt.Sprintf("unexpected %s, expected %s", quote(t.text), expected)}
+}
+
+// parseOr parses: and { OR and }
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{and: false, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: not { AND not }
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("AND") {
+		p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = binary{and: true, left: left, right: right}
+	}
+	return left, nil
+}
+
+// parseNot parses: NOT not | primary
+func (p *parser) parseNot() (node, error) {
+	if p.keyword("NOT") {
+		p.next()
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return negation{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+// parsePrimary parses: ( or ) | comparison
+func (p *parser) parsePrimary() (node, error) {
+	t := p.peek()
+	if t.kind == tokenLParen {
+		p.next()
+		n, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, p.unexpected(closing, "')'")
+		}
+		return n, nil
+	}
+	return p.parseComparison()
+}
+
+// parseComparison parses: property operator values
+func (p *parser) parseComparison() (node, error) {
+	prop := p.next()
+	if prop.kind != tokenWord || isKeyword(prop.text) {
+		return nil, p.unexpected(prop, "a property name or '('")
+	}
+
+	operator, arity, err := p.parseOperator()
+	if err != nil {
+		return nil, err
+	}
+
+	filter := hubspot.Filter{PropertyName: prop.text, Operator: operator}
+	switch arity {
+	case 1:
+		filter.Value, err = p.parseValue(operator)
+	case 2:
+		if filter.Value, err = p.parseValue(operator); err != nil {
+			return nil, err
+		}
+		if !p.keyword("AND") {
+			return nil, p.unexpected(p.peek(), "AND in BETWEEN")
+		}
+		p.next()
+		filter.HighValue, err = p.parseValue(operator)
+	case -1:
+		filter.Values, err = p.parseList(operator)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return comparison{filter: filter}, nil
+}
+
+func (p *parser) parseOperator() (string, int, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenOperator:
+		for _, op := range symbolOperators {
+			if op.symbol == t.text {
+				return op.operator, 1, nil
+			}
+		}
+	case tokenWord:
+		upper := strings.ToUpper(t.text)
+		if upper == "NOT" {
+			// NOT IN, NOT HAS_PROPERTY and NOT MATCHES
+			switch {
+			case p.keyword("IN"):
+				p.next()
+				return "NOT_IN", -1, nil
+			case p.keyword("HAS_PROPERTY"):
+				p.next()
+				return "NOT_HAS_PROPERTY", 0, nil
+			case p.keyword("MATCHES"):
+				p.next()
+				return "NOT_MATCHES", 1, nil
+			}
+			return "", 0, p.unexpected(p.peek(), "IN, HAS_PROPERTY or MATCHES after NOT")
+		}
+		if op, ok := keywordOperators[upper]; ok {
+			return op.operator, op.values, nil
+		}
+	}
+	return "", 0, p.unexpected(t, "an operator such as =, !=, <, >=, ~, IN, BETWEEN or HAS_PROPERTY")
+}
+
+// parseValue parses a quoted string or a run of unquoted words, which are
+// joined with single spaces
+func (p *parser) parseValue(operator string) (string, error) {
+	t := p.peek()
+	if t.kind == tokenString {
+		p.next()
+		if operator == "MATCHES" || operator == "NOT_MATCHES" {
+			// Regular expressions keep their escapes, e.g. '\d+'
+			return t.text, nil
+		}
+		return unescape(t.text), nil
+	}
+	if t.kind != tokenWord || isKeyword(t.text) {
+		return "", p.unexpected(t, "a value")
+	}
+
+	var words []string
+	for t := p.peek(); t.kind == tokenWord && !isKeyword(t.text); t = p.peek() {
+		words = append(words, p.next().text)
+	}
+	return normalizeValue(operator, strings.Join(words, " ")), nil
+}
+
+// parseList parses: ( value { , value } )
+func (p *parser) parseList(operator string) ([]string, error) {
+	if t := p.next(); t.kind != tokenLParen {
+		return nil, p.unexpected(t, "'(' to start the value list")
+	}
+
+	var values []string
+	for {
+		value, err := p.parseValue(operator)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		t := p.next()
+		if t.kind == tokenRParen {
+			return values, nil
+		}
+		if t.kind != tokenComma {
+			return nil, p.unexpected(t, "',' or ')'")
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package query
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+func f(prop, op, value string) hubspot.Filter {
+	return hubspot.Filter{PropertyName: prop, Operator: op, Value: value}
+}
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  [][]hubspot.Filter
+	}{
+		{
+			name:  "single comparison",
+			input: "lifecyclestage = lead",
+			want:  [][]hubspot.Filter{{f("lifecyclestage", "EQ", "lead")}},
+		},
+		{
+			name:  "no spaces",
+			input: "email=ada@example.com",
+			want:  [][]hubspot.Filter{{f("email", "EQ", "ada@example.com")}},
+		},
+		{
+			name:  "and binds tighter than or",
+			input: "a = 1 AND b > 2 OR c != 3",
+			want:  [][]hubspot.Filter{{f("a", "EQ", "1"), f("b", "GT", "2")}, {f("c", "NEQ", "3")}},
+		},
+		{
+			name:  "parentheses distribute",
+			input: "a = 1 AND (b = 2 OR c = 3)",
+			want:  [][]hubspot.Filter{{f("a", "EQ", "1"), f("b", "EQ", "2")}, {f("a", "EQ", "1"), f("c", "EQ", "3")}},
+		},
+		{
+			name:  "request example",
+			input: "lifecyclestage = lead AND createdate >= 2025-01-01 OR (company ~ acme AND NOT email HAS_PROPERTY)",
+			want: [][]hubspot.Filter{
+				{f("lifecyclestage", "EQ", "lead"), f("createdate", "GTE", "1735689600000")},
+				{f("company", "CONTAINS_TOKEN", "acme"), f("email", "NOT_HAS_PROPERTY", "")},
+			},
+		},
+		{
+			name:  "de morgan",
+			input: "NOT (a < 1 OR b IN (x, y))",
+			want:  [][]hubspot.Filter{{f("a", "GTE", "1"), {PropertyName: "b", Operator: "NOT_IN", Values: []string{"x", "y"}}}},
+		},
+		{
+			name:  "between",
+			input: "amount BETWEEN 100 AND 500",
+			want:  [][]hubspot.Filter{{{PropertyName: "amount", Operator: "BETWEEN", Value: "100", HighValue: "500"}}},
+		},
+		{
+			name:  "negated between",
+			input: "NOT amount BETWEEN 100 AND 500",
+			want:  [][]hubspot.Filter{{f("amount", "LT", "100")}, {f("amount", "GT", "500")}},
+		},
+		{
+			name:  "keyword operators are case-insensitive",
+			input: "email has_property and dealstage not in ('closed won', closedlost)",
+			want: [][]hubspot.Filter{{
+				f("email", "HAS_PROPERTY", ""),
+				{PropertyName: "dealstage", Operator: "NOT_IN", Values: []string{"closed won", "closedlost"}},
+			}},
+		},
+		{
+			name:  "unquoted words are joined",
+			input: "company = Acme Corp AND city = New York",
+			want:  [][]hubspot.Filter{{f("company", "EQ", "Acme Corp"), f("city", "EQ", "New York")}},
+		},
+		{
+			name:  "quoted values are kept as typed",
+			input: `notes = "2025-01-01" AND name = 'O\'Brien'`,
+			want:  [][]hubspot.Filter{{f("notes", "EQ", "2025-01-01"), f("name", "EQ", "O'Brien")}},
+		},
+		{
+			name:  "escapes in quoted values",
+			input: `path = "C:\\temp\d" OR path IN ('a\,b', "\"x\"")`,
+			want: [][]hubspot.Filter{
+				{f("path", "EQ", `C:\tempd`)},
+				{{PropertyName: "path", Operator: "IN", Values: []string{"a,b", `"x"`}}},
+			},
+		},
+		{
+			name:  "quotes inside a word",
+			input: "lastname=O'Brien",
+			want:  [][]hubspot.Filter{{f("lastname", "EQ", "O'Brien")}},
+		},
+		{
+			name:  "value with a query string",
+			input: "website=https://x.com/?a=b AND (city = Köln)",
+			want:  [][]hubspot.Filter{{f("website", "EQ", "https://x.com/?a=b"), f("city", "EQ", "Köln")}},
+		},
+		{
+			name:  "timestamps",
+			input: "closedate < 2025-01-01T12:00:00Z",
+			want:  [][]hubspot.Filter{{f("closedate", "LT", "1735732800000")}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups, err := Compile(tt.input)
+			if err != nil {
+				t.Fatalf("Compile failed: %v", err)
+			}
+			var got [][]hubspot.Filter
+			for _, g := range groups {
+				got = append(got, g.Filters)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compile(%q)\n got %+v\nwant %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompile_SyntaxErrors(t *testing.T) {
+	tests := []struct {
+		input string
+		pos   int
+		msg   string
+	}{
+		{"", 1, "empty query"},
+		{"email", 6, "unexpected end of query, expected an operator"},
+		{"email = ", 9, "unexpected end of query, expected a value"},
+		{"(a = 1", 7, "expected ')'"},
+		{"a = 1 b = 2", 9, "unexpected '='"},
+		{"a IN x", 6, "expected '(' to start the value list"},
+		{"a BETWEEN 1 OR 2", 13, "expected AND in BETWEEN"},
+		{`a = "open`, 5, "unterminated string"},
+		{`lifecyclestage = "lead`, 18, "unterminated string"},
+		{"AND = 1", 1, "expected a property name"},
+		{"a NOT = 1", 7, "expected IN, HAS_PROPERTY or MATCHES after NOT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			_, err := Compile(tt.input)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Expected a syntax error, got %v", err)
+			}
+			if syntaxErr.Pos != tt.pos || !strings.Contains(syntaxErr.Msg, tt.msg) {
+				t.Errorf("Expected %q at column %d, got %q at column %d", tt.msg, tt.pos, syntaxErr.Msg, syntaxErr.Pos)
+			}
+		})
+	}
+}
+
+func TestCompile_Limits(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		msg   string
+	}{
+		{"too many groups", "a=1 OR b=1 OR c=1 OR d=1 OR e=1 OR f=1", "6 filter groups"},
+		{"too many filters per group", "a=1 AND b=1 AND c=1 AND d=1 AND e=1 AND f=1 AND g=1", "7 filters"},
+		{"too many filters in total", "(a=1 OR b=1 OR c=1 OR d=1) AND (e=1 AND f=1 AND g=1 AND h=1 AND i=1)", "24 filters"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.input)
+			if err == nil || !strings.Contains(err.Error(), tt.msg) {
+				t.Errorf("Expected error containing %q, got %v", tt.msg, err)
+			}
+		})
+	}
+}
+
+func TestIsExpression(t *testing.T) {
+	tests := map[string]bool{
+		"jane":                   false,
+		"acme corp":              false,
+		"ada@example.com":        false,
+		"email=ada@x.com":        true,
+		"email HAS_PROPERTY":     true,
+		"lead AND customer":      true,
+		"(a)":                    true,
+		`"quoted phrase"`:        false,
+		`'quoted phrase'`:        false,
+		`"a" "b"`:                true,
+		"amount BETWEEN 1 2":     true,
+		"first-name last_name":   false,
+		"O'Brien":                false,
+		`name = "open`:           true,
+		`"open`:                  true,
+		`lifecyclestage = "lead`: true,
+		`a = "open" OR b = "x`:   true,
+	}
+	for input, want := range tests {
+		if got := IsExpression(input); got != want {
+			t.Errorf("IsExpression(%q) = %v, want %v", input, got, want)
+		}
+	}
+}
+
+func TestParseSorts(t *testing.T) {
+	sorts, err := ParseSorts([]string{"createdate:desc", "lastname", "email:ASC"})
+	if err != nil {
+		t.Fatalf("ParseSorts failed: %v", err)
+	}
+	want := []hubspot.Sort{
+		{PropertyName: "createdate", Direction: hubspot.SortDescending},
+		{PropertyName: "lastname", Direction: hubspot.SortAscending},
+		{PropertyName: "email", Direction: hubspot.SortAscending},
+	}
+	if !reflect.DeepEqual(sorts, want) {
+		t.Errorf("Expected %+v, got %+v", want, sorts)
+	}
+
+	for _, spec := range []string{":desc", "email:sideways"} {
+		if _, err := ParseSorts([]string{spec}); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/obay/hscli/internal/hubspot"
+)
+
+// ParseSorts parses sort specs of the form property[:asc|desc], where the
+// direction defaults to ascending
+func ParseSorts(specs []string) ([]hubspot.Sort, error) {
+	var sorts []hubspot.Sort
+	for _, spec := range specs {
+		name, direction, _ := strings.Cut(strings.TrimSpace(spec), ":")
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, fmt.Errorf("invalid sort %q, expected property[:asc|desc]", spec)
+		}
+
+		sort := hubspot.Sort{PropertyName: name, Direction: hubspot.SortAscending}
+		switch strings.ToLower(strings.TrimSpace(direction)) {
+		case "", "asc", "ascending":
+		case "desc", "descending":
+			sort.Direction = hubspot.SortDescending
+		default:
+			return nil, fmt.Errorf("invalid sort direction %q in %q, expected asc or desc", direction, spec)
+		}
+		sorts = append(sorts, sort)
+	}
+	return sorts, nil
+}